@@ -0,0 +1,122 @@
+package promtool
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// The policies of an AlertPolicy, named after the options of the provider
+// setting them.
+const (
+	alertPolicyRequiredLabels      = "required_labels"
+	alertPolicyRequiredAnnotations = "required_annotations"
+	alertPolicyURLAnnotations      = "url_annotations"
+	alertPolicyCamelCaseNames      = "camel_case_names"
+	alertPolicyRequiredGroupLabels = "required_group_labels"
+)
+
+// AlertPolicy is the metadata the alerting rules have to carry, it is
+// enforced by the alert-policy lint category. The zero value enforces
+// nothing.
+type AlertPolicy struct {
+	// RequiredLabels maps the labels every alert must have to their
+	// allowed values, any value is allowed when there are none. The labels
+	// of the group of the rule count.
+	RequiredLabels map[string][]string
+	// RequiredAnnotations are the annotations every alert must have.
+	RequiredAnnotations []string
+	// URLAnnotations are the annotations that must be absolute URLs when
+	// they are set, e.g. runbook_url.
+	URLAnnotations []string
+	// CamelCaseNames requires alert names in CamelCase, e.g.
+	// HighRequestLatency.
+	CamelCaseNames bool
+	// RequiredGroupLabels are the labels every group holding alerting
+	// rules must set.
+	RequiredGroupLabels []string
+}
+
+var camelCase = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+
+// checkAlertPolicy returns the alerting rules of files, and the groups
+// holding them, that do not follow policy.
+func checkAlertPolicy(files []ruleFile, policy *AlertPolicy) []ruleFinding {
+	if policy == nil {
+		return nil
+	}
+
+	requiredLabels := make([]string, 0, len(policy.RequiredLabels))
+	for name := range policy.RequiredLabels {
+		requiredLabels = append(requiredLabels, name)
+	}
+	sort.Strings(requiredLabels)
+
+	var findings []ruleFinding
+	for f, file := range files {
+		for i, group := range file.groups.Groups {
+			violation := func(ruleIndex int, subject, policy, format string, args ...any) {
+				findings = append(findings, ruleFinding{
+					fileIndex:  f,
+					groupIndex: i,
+					ruleIndex:  ruleIndex,
+					message:    fmt.Sprintf("%s violates the %s policy: %s", subject, policy, fmt.Sprintf(format, args...)),
+				})
+			}
+
+			alerts := false
+			for j, rule := range group.Rules {
+				if rule.Alert == "" {
+					continue
+				}
+				alerts = true
+				subject := "alert " + rule.Alert
+
+				if policy.CamelCaseNames && !camelCase.MatchString(rule.Alert) {
+					violation(j, subject, alertPolicyCamelCaseNames, "the name is not in CamelCase")
+				}
+				for _, name := range requiredLabels {
+					value, ok := rule.Labels[name]
+					if !ok {
+						value, ok = group.Labels[name]
+					}
+					allowed := policy.RequiredLabels[name]
+					switch {
+					case !ok:
+						violation(j, subject, alertPolicyRequiredLabels, "label %q is missing", name)
+					case len(allowed) > 0 && !strings.Contains(value, "{{") && !slices.Contains(allowed, value):
+						// Templated values are only known when the alert fires.
+						violation(j, subject, alertPolicyRequiredLabels, "label %q is %q, allowed values are %s", name, value, strings.Join(allowed, ", "))
+					}
+				}
+				for _, name := range policy.RequiredAnnotations {
+					if _, ok := rule.Annotations[name]; !ok {
+						violation(j, subject, alertPolicyRequiredAnnotations, "annotation %q is missing", name)
+					}
+				}
+				for _, name := range policy.URLAnnotations {
+					value, ok := rule.Annotations[name]
+					if !ok {
+						continue
+					}
+					if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+						violation(j, subject, alertPolicyURLAnnotations, "annotation %q is not an absolute URL: %q", name, value)
+					}
+				}
+			}
+
+			if !alerts {
+				continue
+			}
+			for _, name := range policy.RequiredGroupLabels {
+				if _, ok := group.Labels[name]; !ok {
+					violation(-1, "group "+group.Name, alertPolicyRequiredGroupLabels, "label %q is missing", name)
+				}
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,150 @@
+package promtool
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCheckAlertPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  string
+		policy *AlertPolicy
+		want   []string
+	}{
+		{
+			name: "no policy",
+			rules: `
+groups:
+- name: example
+  rules:
+  - alert: high_latency
+    expr: up == 0
+`,
+			want: nil,
+		},
+		{
+			name: "camel case names",
+			rules: `
+groups:
+- name: example
+  rules:
+  - alert: HighRequestLatency
+    expr: up == 0
+  - alert: high_request_latency
+    expr: up == 0
+  - alert: highRequestLatency
+    expr: up == 0
+  - record: job:up:sum
+    expr: sum by (job) (up)
+`,
+			policy: &AlertPolicy{CamelCaseNames: true},
+			want: []string{
+				"groups[0].rules[1]: alert high_request_latency violates the camel_case_names policy: the name is not in CamelCase",
+				"groups[0].rules[2]: alert highRequestLatency violates the camel_case_names policy: the name is not in CamelCase",
+			},
+		},
+		{
+			name: "group labels count as rule labels",
+			rules: `
+groups:
+- name: example
+  labels:
+    severity: critical
+  rules:
+  - alert: InstanceDown
+    expr: up == 0
+  - alert: InstanceFlapping
+    expr: changes(up[5m]) > 2
+    labels:
+      severity: info
+`,
+			policy: &AlertPolicy{RequiredLabels: map[string][]string{"severity": {"critical", "warning"}}},
+			want: []string{
+				`groups[0].rules[1]: alert InstanceFlapping violates the required_labels policy: label "severity" is "info", allowed values are critical, warning`,
+			},
+		},
+		{
+			name: "templated values are skipped",
+			rules: `
+groups:
+- name: example
+  rules:
+  - alert: InstanceDown
+    expr: up == 0
+    labels:
+      severity: '{{ $labels.severity }}'
+  - alert: InstanceFlapping
+    expr: changes(up[5m]) > 2
+`,
+			policy: &AlertPolicy{RequiredLabels: map[string][]string{"severity": {"critical"}}},
+			want: []string{
+				`groups[0].rules[1]: alert InstanceFlapping violates the required_labels policy: label "severity" is missing`,
+			},
+		},
+		{
+			name: "annotations",
+			rules: `
+groups:
+- name: example
+  rules:
+  - alert: InstanceDown
+    expr: up == 0
+    annotations:
+      summary: Instance down
+      runbook_url: https://runbooks.example.com/InstanceDown
+  - alert: InstanceFlapping
+    expr: changes(up[5m]) > 2
+    annotations:
+      runbook_url: runbooks/InstanceFlapping
+`,
+			policy: &AlertPolicy{
+				RequiredAnnotations: []string{"summary"},
+				URLAnnotations:      []string{"runbook_url"},
+			},
+			want: []string{
+				`groups[0].rules[1]: alert InstanceFlapping violates the required_annotations policy: annotation "summary" is missing`,
+				`groups[0].rules[1]: alert InstanceFlapping violates the url_annotations policy: annotation "runbook_url" is not an absolute URL: "runbooks/InstanceFlapping"`,
+			},
+		},
+		{
+			name: "group labels only for groups with alerts",
+			rules: `
+groups:
+- name: alerts
+  rules:
+  - alert: InstanceDown
+    expr: up == 0
+- name: records
+  rules:
+  - record: job:up:sum
+    expr: sum by (job) (up)
+`,
+			policy: &AlertPolicy{RequiredGroupLabels: []string{"team"}},
+			want: []string{
+				`groups[0]: group alerts violates the required_group_labels policy: label "team" is missing`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, errs := parseRuleFile("", []byte(tt.rules))
+			if len(errs) != 0 {
+				t.Fatal(errs)
+			}
+
+			var got []string
+			for _, finding := range checkAlertPolicy([]ruleFile{f}, tt.policy) {
+				path := fmt.Sprintf("groups[%d]", finding.groupIndex)
+				if finding.ruleIndex >= 0 {
+					path += fmt.Sprintf(".rules[%d]", finding.ruleIndex)
+				}
+				got = append(got, path+": "+finding.message)
+			}
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("checkAlertPolicy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -17,14 +17,15 @@ package promtool
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	config_util "github.com/prometheus/common/config"
-	"github.com/prometheus/common/promslog"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/discovery"
 	"github.com/prometheus/prometheus/discovery/file"
@@ -34,96 +35,278 @@ import (
 	"github.com/prometheus/prometheus/notifier"
 	"github.com/prometheus/prometheus/scrape"
 	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
 )
 
-func CheckConfig(content string, checkSyntaxOnly bool) ([]string, error) {
-	cfg, err := config.Load(content, promslog.NewNopLogger())
+const (
+	ConfigSectionConfig        = "config"
+	ConfigSectionRuleFiles     = "rule_files"
+	ConfigSectionScrapeConfigs = "scrape_configs"
+	ConfigSectionAlerting      = "alerting"
+	ConfigSectionRemoteRead    = "remote_read"
+	ConfigSectionStorage       = "storage"
+)
+
+// ConfigDiagnostic describes a single problem found while checking a
+// Prometheus configuration. Name identifies the faulty item inside Section,
+// e.g. the job name of a scrape config or the rule file pattern. For the
+// config section it is the YAML path of the faulty field when known, e.g.
+// scrape_configs[1].scrape_timeout.
+type ConfigDiagnostic struct {
+	Section string
+	Name    string
+	Err     error
+	// Rule is the problem found in a rule file when CheckRuleFiles is set,
+	// with its position in the file. It is nil for the other problems.
+	Rule *Diagnostic
+}
+
+// newRuleConfigDiagnostic wraps d, found in a rule file referenced by the
+// configuration, into a ConfigDiagnostic named after that file.
+func newRuleConfigDiagnostic(d Diagnostic) ConfigDiagnostic {
+	return ConfigDiagnostic{
+		Section: ConfigSectionRuleFiles,
+		Name:    d.File,
+		Err:     fmt.Errorf("%s: %w", d.Position(), d),
+		Rule:    &d,
+	}
+}
+
+func (d ConfigDiagnostic) Error() string {
+	return d.Err.Error()
+}
+
+// ConfigOptions tunes how a Prometheus configuration is checked.
+type ConfigOptions struct {
+	// SyntaxOnly skips the checks of the files referenced by the
+	// configuration.
+	SyntaxOnly bool
+	// Files is where the files referenced by the configuration are looked
+	// up, the local filesystem is used when it is nil.
+	Files FileSystem
+	// CheckRuleFiles parses and lints the rule files referenced by the
+	// configuration, as CheckRules does.
+	CheckRuleFiles bool
+	// BaseDir is the directory relative paths in the configuration are
+	// resolved against, like Prometheus does with the directory of its
+	// configuration file. The working directory is used when it is empty.
+	BaseDir string
+	// FatalWarnings reports warnings as errors.
+	FatalWarnings bool
+	// Rules is how the rule files are linted when CheckRuleFiles is set.
+	Rules RulesOptions
+	// PrometheusVersion is the version of Prometheus the configuration,
+	// and its rule files when CheckRuleFiles is set, are checked against.
+	// Every feature of the vendored Prometheus is accepted when it is
+	// empty.
+	PrometheusVersion string
+	// FeatureFlags are the features enabled with --enable-feature. The
+	// rule files are checked with them when CheckRuleFiles is set.
+	FeatureFlags []string
+	// Agent checks the configuration for Prometheus in agent mode, like
+	// the --agent flag of promtool check config.
+	Agent bool
+}
+
+// CheckConfig checks content and returns the rule files it references and
+// the warnings raised, or the first problem found.
+func CheckConfig(content string, opts ConfigOptions) ([]string, []ConfigDiagnostic, error) {
+	ruleFiles, diags, warnings := checkConfig(content, opts)
+	if len(diags) > 0 {
+		return nil, warnings, diags[0].Err
+	}
+	return ruleFiles, warnings, nil
+}
+
+// ValidateConfig checks content like CheckConfig does, but keeps going after
+// a problem is found and returns all of them along with the warnings.
+func ValidateConfig(content string, opts ConfigOptions) ([]ConfigDiagnostic, []ConfigDiagnostic) {
+	_, diags, warnings := checkConfig(content, opts)
+	return diags, warnings
+}
+
+func checkConfig(content string, opts ConfigOptions) ([]string, []ConfigDiagnostic, []ConfigDiagnostic) {
+	checkSyntaxOnly := opts.SyntaxOnly
+	fsys := opts.Files
+	if fsys == nil {
+		fsys = OSFileSystem{}
+	}
+
+	var diags, warnings []ConfigDiagnostic
+	report := func(section, name string, errs ...error) {
+		for _, err := range errs {
+			diags = append(diags, ConfigDiagnostic{Section: section, Name: name, Err: err})
+		}
+	}
+	warnDiagnostic := func(d ConfigDiagnostic) {
+		if opts.FatalWarnings {
+			diags = append(diags, d)
+			return
+		}
+		warnings = append(warnings, d)
+	}
+	warn := func(section, name string, err error) {
+		warnDiagnostic(ConfigDiagnostic{Section: section, Name: name, Err: err})
+	}
+
+	features, featureWarnings := parseFeatureFlags(opts.FeatureFlags)
+	for _, w := range featureWarnings {
+		warn(ConfigSectionConfig, "", w)
+	}
+	target, err := parseTargetVersion(opts.PrometheusVersion)
 	if err != nil {
-		return nil, err
+		report(ConfigSectionConfig, "", err)
+		return nil, diags, warnings
+	}
+	// Loading a configuration requires UTF-8 validation, the legacy one is
+	// only used for the rule files.
+	defer useFeatures(features.forVersion(target), model.UTF8Validation)()
+
+	logger := newWarningLogger(func(err error) { warn(ConfigSectionConfig, "", err) })
+	cfg, err := config.Load(content, logger)
+	if err != nil {
+		report(ConfigSectionConfig, configErrorPath(content, err), err)
+		return nil, diags, warnings
+	}
+
+	if opts.Agent {
+		agentErrs, agentWarnings := checkAgentConfig(cfg)
+		diags = append(diags, agentErrs...)
+		for _, w := range agentWarnings {
+			warn(w.Section, w.Name, w.Err)
+		}
+	}
+
+	compatErrs, compatWarnings := checkConfigCompatibility(content, target)
+	diags = append(diags, compatErrs...)
+	for _, w := range compatWarnings {
+		warn(w.Section, w.Name, w.Err)
+	}
+	for _, w := range checkConfigFeatures(content, features) {
+		warn(w.Section, w.Name, w.Err)
+	}
+	if opts.BaseDir != "" {
+		cfg.SetDirectory(opts.BaseDir)
+	}
+
+	if opts.CheckRuleFiles && checkSyntaxOnly {
+		warn(ConfigSectionRuleFiles, "", errors.New("the rule files are not checked when only the syntax of the configuration is checked"))
+	}
+	if opts.CheckRuleFiles && opts.Agent {
+		warn(ConfigSectionRuleFiles, "", errors.New("the rule files are not checked in agent mode"))
 	}
 
 	var ruleFiles []string
-	if !checkSyntaxOnly {
+	// Rule files are not allowed in agent mode, this has already been
+	// reported.
+	if !checkSyntaxOnly && !opts.Agent {
 		for _, rf := range cfg.RuleFiles {
-			rfs, err := filepath.Glob(rf)
+			rfs, err := fsys.Glob(rf)
 			if err != nil {
-				return nil, err
+				report(ConfigSectionRuleFiles, rf, err)
+				continue
 			}
 			// If an explicit file was given, error if it is not accessible.
 			if !strings.Contains(rf, "*") {
 				if len(rfs) == 0 {
-					return nil, fmt.Errorf("%q does not point to an existing file", rf)
+					report(ConfigSectionRuleFiles, rf, fmt.Errorf("%q does not point to an existing file", rf))
+					continue
 				}
-				if err := checkFileExists(rfs[0]); err != nil {
-					return nil, fmt.Errorf("error checking rule file %q: %w", rfs[0], err)
+				if err := checkFileExists(fsys, rfs[0]); err != nil {
+					report(ConfigSectionRuleFiles, rf, fmt.Errorf("error checking rule file %q: %w", rfs[0], err))
+					continue
 				}
 			}
 			ruleFiles = append(ruleFiles, rfs...)
 		}
 	}
 
+	if opts.CheckRuleFiles {
+		// The rule files are validated with the names accepted by the
+		// targeted version, useFeatures restores the scheme.
+		model.NameValidationScheme = features.nameValidationScheme(target)
+
+		contents := map[string]string{}
+		for _, rf := range ruleFiles {
+			content, err := fsys.ReadFile(rf)
+			if err != nil {
+				report(ConfigSectionRuleFiles, rf, fmt.Errorf("error reading rule file %q: %w", rf, err))
+				continue
+			}
+			contents[rf] = string(content)
+		}
+		lintSettings, lintWarnings := opts.Rules.lintConfig()
+		for _, w := range lintWarnings {
+			warn(ConfigSectionRuleFiles, "", w)
+		}
+		ruleErrs, ruleWarnings := validateRuleFiles(contents, lintSettings, target)
+		for _, d := range ruleErrs {
+			diags = append(diags, newRuleConfigDiagnostic(d))
+		}
+		for _, d := range ruleWarnings {
+			warnDiagnostic(newRuleConfigDiagnostic(d))
+		}
+		model.NameValidationScheme = model.UTF8Validation
+	}
+
 	var scfgs []*config.ScrapeConfig
 	if checkSyntaxOnly {
 		scfgs = cfg.ScrapeConfigs
 	} else {
 		var err error
-		scfgs, err = cfg.GetScrapeConfigs()
+		scfgs, err = getScrapeConfigs(cfg, fsys)
 		if err != nil {
-			return nil, fmt.Errorf("error loading scrape configs: %w", err)
+			report(ConfigSectionScrapeConfigs, "", fmt.Errorf("error loading scrape configs: %w", err))
+			scfgs = cfg.ScrapeConfigs
 		}
 	}
 
 	for _, scfg := range scfgs {
 		if !checkSyntaxOnly && scfg.HTTPClientConfig.Authorization != nil {
-			if err := checkFileExists(scfg.HTTPClientConfig.Authorization.CredentialsFile); err != nil {
-				return nil, fmt.Errorf("error checking authorization credentials or bearer token file %q: %w", scfg.HTTPClientConfig.Authorization.CredentialsFile, err)
+			if err := checkFileExists(fsys, scfg.HTTPClientConfig.Authorization.CredentialsFile); err != nil {
+				report(ConfigSectionScrapeConfigs, scfg.JobName, fmt.Errorf("error checking authorization credentials or bearer token file %q: %w", scfg.HTTPClientConfig.Authorization.CredentialsFile, err))
 			}
 		}
 
-		if err := checkTLSConfig(scfg.HTTPClientConfig.TLSConfig, checkSyntaxOnly); err != nil {
-			return nil, err
-		}
+		report(ConfigSectionScrapeConfigs, scfg.JobName, checkTLSConfig(fsys, scfg.HTTPClientConfig.TLSConfig, checkSyntaxOnly)...)
 
 		for _, c := range scfg.ServiceDiscoveryConfigs {
 			switch c := c.(type) {
 			case *kubernetes.SDConfig:
-				if err := checkTLSConfig(c.HTTPClientConfig.TLSConfig, checkSyntaxOnly); err != nil {
-					return nil, err
-				}
+				report(ConfigSectionScrapeConfigs, scfg.JobName, checkTLSConfig(fsys, c.HTTPClientConfig.TLSConfig, checkSyntaxOnly)...)
 			case *file.SDConfig:
 				if checkSyntaxOnly {
 					break
 				}
 				for _, file := range c.Files {
-					files, err := filepath.Glob(file)
+					files, err := fsys.Glob(file)
 					if err != nil {
-						return nil, err
+						report(ConfigSectionScrapeConfigs, scfg.JobName, err)
+						continue
 					}
 					if len(files) != 0 {
 						for _, f := range files {
 							var targetGroups []*targetgroup.Group
-							targetGroups, err = checkSDFile(f)
+							targetGroups, err = checkSDFile(fsys, f)
 							if err != nil {
-								return nil, fmt.Errorf("checking SD file %q: %w", file, err)
-							}
-							if err := checkTargetGroupsForScrapeConfig(targetGroups, scfg); err != nil {
-								return nil, err
+								report(ConfigSectionScrapeConfigs, scfg.JobName, fmt.Errorf("checking SD file %q: %w", file, err))
+								continue
 							}
+							report(ConfigSectionScrapeConfigs, scfg.JobName, checkTargetGroupsForScrapeConfig(targetGroups, scfg)...)
 						}
 						continue
 					}
-					fmt.Printf("  WARNING: file %q for file_sd in scrape job %q does not exist\n", file, scfg.JobName)
+					warn(ConfigSectionScrapeConfigs, scfg.JobName, fmt.Errorf("file %q for file_sd in scrape job %q does not exist", file, scfg.JobName))
 				}
 			case discovery.StaticConfig:
-				if err := checkTargetGroupsForScrapeConfig(c, scfg); err != nil {
-					return nil, err
-				}
+				report(ConfigSectionScrapeConfigs, scfg.JobName, checkTargetGroupsForScrapeConfig(c, scfg)...)
 			}
 		}
 	}
 
 	alertConfig := cfg.AlertingConfig
-	for _, amcfg := range alertConfig.AlertmanagerConfigs {
+	for i, amcfg := range alertConfig.AlertmanagerConfigs {
+		name := fmt.Sprintf("alertmanagers[%d]", i)
 		for _, c := range amcfg.ServiceDiscoveryConfigs {
 			switch c := c.(type) {
 			case *file.SDConfig:
@@ -131,69 +314,162 @@ func CheckConfig(content string, checkSyntaxOnly bool) ([]string, error) {
 					break
 				}
 				for _, file := range c.Files {
-					files, err := filepath.Glob(file)
+					files, err := fsys.Glob(file)
 					if err != nil {
-						return nil, err
+						report(ConfigSectionAlerting, name, err)
+						continue
 					}
 					if len(files) != 0 {
 						for _, f := range files {
 							var targetGroups []*targetgroup.Group
-							targetGroups, err = checkSDFile(f)
+							targetGroups, err = checkSDFile(fsys, f)
 							if err != nil {
-								return nil, fmt.Errorf("checking SD file %q: %w", file, err)
+								report(ConfigSectionAlerting, name, fmt.Errorf("checking SD file %q: %w", file, err))
+								continue
 							}
 
-							if err := checkTargetGroupsForAlertmanager(targetGroups, amcfg); err != nil {
-								return nil, err
-							}
+							report(ConfigSectionAlerting, name, checkTargetGroupsForAlertmanager(targetGroups, amcfg)...)
 						}
 						continue
 					}
-					fmt.Printf("  WARNING: file %q for file_sd in alertmanager config does not exist\n", file)
+					warn(ConfigSectionAlerting, name, fmt.Errorf("file %q for file_sd in alertmanager config does not exist", file))
 				}
 			case discovery.StaticConfig:
-				if err := checkTargetGroupsForAlertmanager(c, amcfg); err != nil {
-					return nil, err
+				report(ConfigSectionAlerting, name, checkTargetGroupsForAlertmanager(c, amcfg)...)
+			}
+		}
+	}
+	return ruleFiles, diags, warnings
+}
+
+// checkAgentConfig returns the sections of cfg that Prometheus rejects in
+// agent mode, as config.LoadFile does, and warnings for the ones it ignores.
+func checkAgentConfig(cfg *config.Config) ([]ConfigDiagnostic, []ConfigDiagnostic) {
+	var errs, warnings []ConfigDiagnostic
+	if len(cfg.AlertingConfig.AlertmanagerConfigs) > 0 || len(cfg.AlertingConfig.AlertRelabelConfigs) > 0 {
+		errs = append(errs, ConfigDiagnostic{Section: ConfigSectionAlerting, Err: errors.New("field alerting is not allowed in agent mode")})
+	}
+	for _, rf := range cfg.RuleFiles {
+		errs = append(errs, ConfigDiagnostic{Section: ConfigSectionRuleFiles, Name: rf, Err: errors.New("field rule_files is not allowed in agent mode")})
+	}
+	for _, rr := range cfg.RemoteReadConfigs {
+		errs = append(errs, ConfigDiagnostic{Section: ConfigSectionRemoteRead, Name: rr.Name, Err: errors.New("field remote_read is not allowed in agent mode")})
+	}
+
+	if cfg.StorageConfig.TSDBConfig != nil {
+		warnings = append(warnings, ConfigDiagnostic{Section: ConfigSectionStorage, Name: "tsdb", Err: errors.New("storage.tsdb has no effect in agent mode")})
+	}
+	if cfg.StorageConfig.ExemplarsConfig != nil {
+		warnings = append(warnings, ConfigDiagnostic{Section: ConfigSectionStorage, Name: "exemplars", Err: errors.New("storage.exemplars has no effect in agent mode")})
+	}
+	if len(cfg.RemoteWriteConfigs) == 0 {
+		warnings = append(warnings, ConfigDiagnostic{Section: ConfigSectionConfig, Err: errors.New("no remote_write is configured, the samples scraped in agent mode are not sent anywhere")})
+	}
+	return errs, warnings
+}
+
+var configErrorJobName = regexp.MustCompile(`job name ("(?:[^"\\]|\\.)*")`)
+
+// configErrorPath returns the path, as returned by findYAMLPath, of the
+// item of the configuration content that err, returned by config.Load, is
+// about. It is found from the line of YAML errors, or from the job name
+// scrape config errors mention. An empty string is returned when unknown.
+func configErrorPath(content string, err error) string {
+	var doc yaml3.Node
+	if yaml3.Unmarshal([]byte(content), &doc) != nil {
+		return ""
+	}
+
+	msg := err.Error()
+	if m := yamlLine.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return yamlPathAt(&doc, line, 0)
+	}
+	if m := configErrorJobName.FindStringSubmatch(msg); m != nil {
+		name, _ := strconv.Unquote(m[1])
+		// The last job is the duplicate when the name is used twice.
+		var path string
+		for _, match := range findYAMLPath(&doc, "scrape_configs[]") {
+			if _, job := mappingValue(match.node, "job_name"); job != nil && job.Value == name {
+				path = match.path
+			}
+		}
+		return path
+	}
+	return ""
+}
+
+// getScrapeConfigs mirrors config.Config.GetScrapeConfigs, reading the
+// scrape config files from fsys.
+func getScrapeConfigs(c *config.Config, fsys FileSystem) ([]*config.ScrapeConfig, error) {
+	scfgs := make([]*config.ScrapeConfig, len(c.ScrapeConfigs))
+	jobNames := map[string]string{}
+	for i, scfg := range c.ScrapeConfigs {
+		jobNames[scfg.JobName] = "main config file"
+		scfgs[i] = scfg
+	}
+
+	// Re-read and validate the dynamic scrape config rules.
+	for _, pat := range c.ScrapeConfigFiles {
+		fs, err := fsys.Glob(pat)
+		if err != nil {
+			// The only error can be a bad pattern.
+			return nil, fmt.Errorf("error retrieving scrape config files for %q: %w", pat, err)
+		}
+		for _, filename := range fs {
+			cfg := config.ScrapeConfigs{}
+			content, err := fsys.ReadFile(filename)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", filename, err)
+			}
+			err = yaml.UnmarshalStrict(content, &cfg)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", filename, err)
+			}
+			for _, scfg := range cfg.ScrapeConfigs {
+				if err := scfg.Validate(c.GlobalConfig); err != nil {
+					return nil, fmt.Errorf("%q: %w", filename, err)
 				}
+
+				if f, ok := jobNames[scfg.JobName]; ok {
+					return nil, fmt.Errorf("%q: %w", filename, fmt.Errorf("found multiple scrape configs with job name %q, first found in %s", scfg.JobName, f))
+				}
+				jobNames[scfg.JobName] = fmt.Sprintf("%q", filename)
+
+				scfg.SetDirectory(filepath.Dir(filename))
+				scfgs = append(scfgs, scfg)
 			}
 		}
 	}
-	return ruleFiles, nil
+	return scfgs, nil
 }
 
-func checkTargetGroupsForScrapeConfig(targetGroups []*targetgroup.Group, scfg *config.ScrapeConfig) error {
+func checkTargetGroupsForScrapeConfig(targetGroups []*targetgroup.Group, scfg *config.ScrapeConfig) []error {
 	var targets []*scrape.Target
+	var errs []error
 	lb := labels.NewBuilder(labels.EmptyLabels())
 	for _, tg := range targetGroups {
 		var failures []error
 		targets, failures = scrape.TargetsFromGroup(tg, scfg, targets, lb)
-		if len(failures) > 0 {
-			first := failures[0]
-			return first
-		}
+		errs = append(errs, failures...)
 	}
 
-	return nil
+	return errs
 }
 
-func checkTargetGroupsForAlertmanager(targetGroups []*targetgroup.Group, amcfg *config.AlertmanagerConfig) error {
+func checkTargetGroupsForAlertmanager(targetGroups []*targetgroup.Group, amcfg *config.AlertmanagerConfig) []error {
+	var errs []error
 	for _, tg := range targetGroups {
 		if _, _, err := notifier.AlertmanagerFromGroup(tg, amcfg); err != nil {
-			return err
+			errs = append(errs, err)
 		}
 	}
 
-	return nil
+	return errs
 }
 
-func checkSDFile(filename string) ([]*targetgroup.Group, error) {
-	fd, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer fd.Close()
-
-	content, err := io.ReadAll(fd)
+func checkSDFile(fsys FileSystem, filename string) ([]*targetgroup.Group, error) {
+	content, err := fsys.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -222,33 +498,33 @@ func checkSDFile(filename string) ([]*targetgroup.Group, error) {
 	return targetGroups, nil
 }
 
-func checkTLSConfig(tlsConfig config_util.TLSConfig, checkSyntaxOnly bool) error {
+func checkTLSConfig(fsys FileSystem, tlsConfig config_util.TLSConfig, checkSyntaxOnly bool) []error {
 	if len(tlsConfig.CertFile) > 0 && len(tlsConfig.KeyFile) == 0 {
-		return fmt.Errorf("client cert file %q specified without client key file", tlsConfig.CertFile)
+		return []error{fmt.Errorf("client cert file %q specified without client key file", tlsConfig.CertFile)}
 	}
 	if len(tlsConfig.KeyFile) > 0 && len(tlsConfig.CertFile) == 0 {
-		return fmt.Errorf("client key file %q specified without client cert file", tlsConfig.KeyFile)
+		return []error{fmt.Errorf("client key file %q specified without client cert file", tlsConfig.KeyFile)}
 	}
 
 	if checkSyntaxOnly {
 		return nil
 	}
 
-	if err := checkFileExists(tlsConfig.CertFile); err != nil {
-		return fmt.Errorf("error checking client cert file %q: %w", tlsConfig.CertFile, err)
+	var errs []error
+	if err := checkFileExists(fsys, tlsConfig.CertFile); err != nil {
+		errs = append(errs, fmt.Errorf("error checking client cert file %q: %w", tlsConfig.CertFile, err))
 	}
-	if err := checkFileExists(tlsConfig.KeyFile); err != nil {
-		return fmt.Errorf("error checking client key file %q: %w", tlsConfig.KeyFile, err)
+	if err := checkFileExists(fsys, tlsConfig.KeyFile); err != nil {
+		errs = append(errs, fmt.Errorf("error checking client key file %q: %w", tlsConfig.KeyFile, err))
 	}
 
-	return nil
+	return errs
 }
 
-func checkFileExists(fn string) error {
+func checkFileExists(fsys FileSystem, fn string) error {
 	// Nothing set, nothing to error on.
 	if fn == "" {
 		return nil
 	}
-	_, err := os.Stat(fn)
-	return err
+	return fsys.Stat(fn)
 }
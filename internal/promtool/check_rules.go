@@ -16,90 +16,558 @@
 package promtool
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/rulefmt"
+	"gopkg.in/yaml.v3"
 )
 
-func CheckRules(content string, resp *function.RunResponse) bool {
-	rgs, errs := rulefmt.Parse([]byte(content), false)
+var errLint = errors.New("lint error")
+
+// RulesOptions tunes how rules are checked, like the --lint and --lint-fatal
+// flags of promtool check rules.
+type RulesOptions struct {
+	// Lint is a comma separated list of lint categories: all,
+	// duplicate-rules, duplicate-groups, alert-policy,
+	// recording-rule-names, promql-anti-patterns or none. all does not
+	// enable recording-rule-names and promql-anti-patterns.
+	Lint string
+	// LintFatal reports lint findings as errors instead of warnings.
+	LintFatal bool
+	// PrometheusVersion is the version of Prometheus the rules are checked
+	// against, e.g. 2.53.0. Every feature of the vendored Prometheus is
+	// accepted when it is empty.
+	PrometheusVersion string
+	// FeatureFlags are the features enabled with --enable-feature, such
+	// as promql-experimental-functions or utf8-names.
+	FeatureFlags []string
+	// AlertPolicy is the metadata the alerting rules have to carry when the
+	// alert-policy lint category is enabled. Nothing is enforced when it
+	// is nil.
+	AlertPolicy *AlertPolicy
+}
+
+// lintConfig returns the lint configuration of o, along with the unknown
+// lint options as warnings.
+func (o RulesOptions) lintConfig() (lintConfig, []error) {
+	ls, warnings := newLintConfig(o.Lint, o.LintFatal)
+	ls.alertPolicy = o.AlertPolicy
+	return ls, warnings
+}
+
+// DefaultRulesOptions enables every lint category and makes the findings
+// fatal.
+var DefaultRulesOptions = RulesOptions{
+	Lint:      lintOptionAll,
+	LintFatal: true,
+}
+
+// CheckRules checks content and sets resp.Error to the problems found.
+// It returns whether the check failed, along with the non fatal lint
+// findings and the unknown lint options and feature flags.
+func CheckRules(content string, opts RulesOptions, resp *function.RunResponse) (bool, []error) {
+	lintSettings, warnings := opts.lintConfig()
+	features, featureWarnings := parseFeatureFlags(opts.FeatureFlags)
+	warnings = append(warnings, featureWarnings...)
+	target, err := parseTargetVersion(opts.PrometheusVersion)
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, &function.FuncError{Text: err.Error()})
+		return true, warnings
+	}
+	defer useFeatures(features.forVersion(target), features.nameValidationScheme(target))()
+
+	rf, errs := parseRuleFile("", []byte(content))
 	for _, e := range errs {
 		if e != nil {
 			resp.Error = function.ConcatFuncErrors(resp.Error, &function.FuncError{Text: e.Error()})
-			return true
+			return true, warnings
 		}
 	}
 
-	_, errs = checkRuleGroups(rgs, newLintConfig("all", true))
+	failed := false
+	_, errs = checkRuleGroups([]ruleFile{rf}, lintSettings)
 	for _, e := range errs {
-		if e != nil {
-			resp.Error = function.ConcatFuncErrors(resp.Error, &function.FuncError{Text: e.Error()})
-			return true
+		if e == nil {
+			continue
 		}
+		if errors.Is(e, errLint) && !lintSettings.fatal {
+			warnings = append(warnings, e)
+			continue
+		}
+		resp.Error = function.ConcatFuncErrors(resp.Error, &function.FuncError{Text: e.Error()})
+		failed = true
+	}
+	if failed {
+		return true, warnings
+	}
+
+	compatErrs, compatWarnings := checkRulesCompatibility([]ruleFile{rf}, target)
+	for _, w := range compatWarnings {
+		warnings = append(warnings, w)
 	}
-	return false
+	for _, e := range compatErrs {
+		resp.Error = function.ConcatFuncErrors(resp.Error, &function.FuncError{Text: e.Error()})
+	}
+	if len(compatErrs) > 0 {
+		return true, warnings
+	}
+	return false, warnings
+}
+
+// ParseRules parses and validates the rules content like rulefmt.Parse does,
+// with the PromQL parser configured for opts. Unknown feature flags are
+// returned as warnings, and the problems found as errors.
+func ParseRules(content string, opts PromQLOptions) (*rulefmt.RuleGroups, []error, []error) {
+	features, warnings := parseFeatureFlags(opts.FeatureFlags)
+	defer useFeatures(features, model.UTF8Validation)()
+
+	rgs, errs := rulefmt.Parse([]byte(content), false)
+	return rgs, warnings, errs
+}
+
+// ValidateRules parses and lints content like CheckRules does, but collects
+// every problem instead of stopping at the first one. Lint findings are
+// reported as warnings when the lint configuration is not fatal.
+func ValidateRules(content string, opts RulesOptions) (errs []Diagnostic, warnings []Diagnostic) {
+	return ValidateRuleFiles(map[string]string{"": content}, opts)
+}
+
+// ValidateRuleFiles validates the rules documents in contents, keyed by file
+// name, like ValidateRules does. The documents are linted together so that
+// duplicate rules and groups spanning several files are found, and every
+// Diagnostic is attributed to the file it comes from.
+func ValidateRuleFiles(contents map[string]string, opts RulesOptions) (errs []Diagnostic, warnings []Diagnostic) {
+	lintSettings, lintWarnings := opts.lintConfig()
+	for _, w := range lintWarnings {
+		warnings = append(warnings, Diagnostic{Kind: DiagnosticKindLint, Message: w.Error()})
+	}
+	features, featureWarnings := parseFeatureFlags(opts.FeatureFlags)
+	for _, w := range featureWarnings {
+		warnings = append(warnings, Diagnostic{Kind: DiagnosticKindValidation, Message: w.Error()})
+	}
+	target, err := parseTargetVersion(opts.PrometheusVersion)
+	if err != nil {
+		return []Diagnostic{{Kind: DiagnosticKindCompatibility, Message: err.Error()}}, warnings
+	}
+	defer useFeatures(features.forVersion(target), features.nameValidationScheme(target))()
+
+	ruleErrs, ruleWarnings := validateRuleFiles(contents, lintSettings, target)
+	return append(errs, ruleErrs...), append(warnings, ruleWarnings...)
+}
+
+// validateRuleFiles validates the rules documents in contents like
+// ValidateRuleFiles does, with the PromQL parser already configured.
+func validateRuleFiles(contents map[string]string, lintSettings lintConfig, target Version) (errs []Diagnostic, warnings []Diagnostic) {
+	names := make([]string, 0, len(contents))
+	for name := range contents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var files []ruleFile
+	docs := map[string]*yaml.Node{}
+	for _, name := range names {
+		// The name is set after parsing so that the errors are not
+		// prefixed with it, the position they start with is needed to
+		// build the diagnostics.
+		rf, parseErrs := parseRuleFile("", []byte(contents[name]))
+		rf.name = name
+		docs[name] = &rf.doc
+
+		var diags []Diagnostic
+		if rf.groups == nil {
+			diags = newYAMLDiagnostics(parseErrs)
+		} else {
+			for _, e := range parseErrs {
+				diags = append(diags, newRuleFileDiagnostic(e))
+			}
+			setGroupIndexes(diags, rf)
+			files = append(files, rf)
+		}
+		for i := range diags {
+			diags[i].File = name
+		}
+		errs = append(errs, diags...)
+	}
+
+	lints := lintRuleGroups(files, lintSettings)
+	if lintSettings.fatal {
+		errs = append(errs, lints...)
+	} else {
+		warnings = append(warnings, lints...)
+	}
+
+	compatErrs, compatWarnings := checkRulesCompatibility(files, target)
+	errs = append(errs, compatErrs...)
+	warnings = append(warnings, compatWarnings...)
+
+	setDiagnosticPaths(errs, docs)
+	setDiagnosticPaths(warnings, docs)
+	return errs, warnings
+}
+
+// setDiagnosticPaths sets the Path of the diagnostics from the documents
+// they come from, keyed by file name. The path of the rule is used when the
+// position of the problem is unknown.
+func setDiagnosticPaths(diags []Diagnostic, docs map[string]*yaml.Node) {
+	for i, d := range diags {
+		doc, ok := docs[d.File]
+		if !ok {
+			continue
+		}
+		if d.Line != 0 {
+			diags[i].Path = yamlPathAt(doc, d.Line, d.Column)
+		}
+		if diags[i].Path == "" && d.ruleIndex != 0 {
+			diags[i].Path = fmt.Sprintf("groups[%d].rules[%d]", d.groupIndex, d.ruleIndex-1)
+		}
+	}
+}
+
+// repeatedGroup matches the error of rulefmt.Parse about a group reusing
+// the name of a previous group of the file.
+var repeatedGroup = regexp.MustCompile(`^groupname: "(.*)" is repeated in the same file$`)
+
+// setGroupIndexes sets the groupIndex of the diagnostics of the rules of
+// file, built from the errors of rulefmt.Parse. rulefmt only gives the name
+// of their group, but it reports the problems group after group, starting
+// with the error about a reused name, so each diagnostic is matched to the
+// first group with its name from the group of the previous ones.
+func setGroupIndexes(diags []Diagnostic, file ruleFile) {
+	groups := file.groups.Groups
+	group := 0
+	for i, d := range diags {
+		if m := repeatedGroup.FindStringSubmatch(d.Message); m != nil {
+			for j := group + 1; j < len(groups); j++ {
+				if groups[j].Name == m[1] {
+					group = j
+					// The error has no position, it is the one of the
+					// group reusing the name.
+					if d.Line == 0 && j < len(file.groupPositions) {
+						diags[i].Line = file.groupPositions[j].Line
+						diags[i].Column = file.groupPositions[j].Column
+					}
+					break
+				}
+			}
+			continue
+		}
+		if d.ruleIndex == 0 {
+			continue
+		}
+		for j := group; j < len(groups); j++ {
+			if groups[j].Name == d.Group {
+				group = j
+				break
+			}
+		}
+		diags[i].groupIndex = group
+	}
+}
+
+// ruleFile is a parsed rules document. name is the file the document was
+// read from, it is empty when the rules were not read from a file.
+type ruleFile struct {
+	name           string
+	groups         *rulefmt.RuleGroups
+	doc            yaml.Node
+	groupPositions []yaml.Node
+	positions      [][]yaml.Node
+}
+
+// parseRuleFile parses and validates content. groups is nil when content is
+// not a valid YAML rules document. Errors are prefixed with name if set.
+func parseRuleFile(name string, content []byte) (ruleFile, []error) {
+	rgs, errs := rulefmt.Parse(content, false)
+	if name != "" {
+		for i := range errs {
+			errs[i] = fmt.Errorf("%s: %w", name, errs[i])
+		}
+	}
+	// Decoding errors are already reported by rulefmt.
+	var doc yaml.Node
+	_ = yaml.Unmarshal(content, &doc)
+
+	groupPositions, positions := rulePositions(content)
+	return ruleFile{
+		name:           name,
+		groups:         rgs,
+		doc:            doc,
+		groupPositions: groupPositions,
+		positions:      positions,
+	}, errs
 }
 
 type lintConfig struct {
-	all            bool
-	duplicateRules bool
-	fatal          bool
+	all             bool
+	duplicateRules  bool
+	duplicateGroups bool
+	alertPolicy     *AlertPolicy
+	alertPolicyLint bool
+	// recordingRuleNames and promQLAntiPatterns are only enabled
+	// explicitly.
+	recordingRuleNames bool
+	promQLAntiPatterns bool
+	fatal              bool
 }
 
-func newLintConfig(stringVal string, fatal bool) lintConfig {
+// newLintConfig parses the comma separated lint options in stringVal. Unknown
+// options are ignored and returned as warnings.
+func newLintConfig(stringVal string, fatal bool) (lintConfig, []error) {
 	items := strings.Split(stringVal, ",")
 	ls := lintConfig{
 		fatal: fatal,
 	}
+	var warnings []error
 	for _, setting := range items {
 		switch setting {
 		case lintOptionAll:
 			ls.all = true
 		case lintOptionDuplicateRules:
 			ls.duplicateRules = true
-		case lintOptionNone:
+		case lintOptionDuplicateGroups:
+			ls.duplicateGroups = true
+		case lintOptionAlertPolicy:
+			ls.alertPolicyLint = true
+		case lintOptionRecordingRuleNames:
+			ls.recordingRuleNames = true
+		case lintOptionPromQLAntiPatterns:
+			ls.promQLAntiPatterns = true
+		case lintOptionNone, "":
 		default:
-			fmt.Printf("WARNING: unknown lint option %s\n", setting)
+			warnings = append(warnings, fmt.Errorf("unknown lint option %s", setting))
 		}
 	}
-	return ls
+	return ls, warnings
 }
 
 func (ls lintConfig) lintDuplicateRules() bool {
 	return ls.all || ls.duplicateRules
 }
 
-func checkRuleGroups(rgs *rulefmt.RuleGroups, lintSettings lintConfig) (int, []error) {
+func (ls lintConfig) lintDuplicateGroups() bool {
+	return ls.all || ls.duplicateGroups
+}
+
+func (ls lintConfig) lintAlertPolicy() bool {
+	return ls.all || ls.alertPolicyLint
+}
+
+func (ls lintConfig) lintRecordingRuleNames() bool {
+	return ls.recordingRuleNames
+}
+
+func (ls lintConfig) lintPromQLAntiPatterns() bool {
+	return ls.promQLAntiPatterns
+}
+
+func checkRuleGroups(files []ruleFile, lintSettings lintConfig) (int, []error) {
 	numRules := 0
-	for _, rg := range rgs.Groups {
-		numRules += len(rg.Rules)
+	for _, f := range files {
+		for _, rg := range f.groups.Groups {
+			numRules += len(rg.Rules)
+		}
 	}
 
+	var errs []error
 	if lintSettings.lintDuplicateRules() {
-		dRules := checkDuplicates(rgs.Groups)
+		dRules := checkDuplicates(files)
 		if len(dRules) != 0 {
 			errMessage := fmt.Sprintf("%d duplicate rule(s) found.\n", len(dRules))
 			for _, n := range dRules {
+				if name := files[n.fileIndex].name; name != "" {
+					errMessage += fmt.Sprintf("File: %s\n", name)
+				}
 				errMessage += fmt.Sprintf("Metric: %s\nLabel(s):\n", n.metric)
 				n.label.Range(func(l labels.Label) {
 					errMessage += fmt.Sprintf("\t%s: %s\n", l.Name, l.Value)
 				})
 			}
 			errMessage += "Might cause inconsistency while recording expressions"
-			return 0, []error{fmt.Errorf("%w %s", fmt.Errorf("lint error"), errMessage)}
+			errs = append(errs, fmt.Errorf("%w %s", errLint, errMessage))
 		}
 	}
 
+	for _, f := range ruleFindings(files, lintSettings) {
+		errs = append(errs, fmt.Errorf("%w %s", errLint, f.message))
+	}
+	if len(errs) > 0 {
+		return 0, errs
+	}
 	return numRules, nil
 }
 
+// ruleFinding is a lint finding about a rule of files, or about a group
+// when ruleIndex is negative. field is the field of the rule the finding is
+// about, e.g. expr, it is empty when it is about the whole rule.
+type ruleFinding struct {
+	fileIndex  int
+	groupIndex int
+	ruleIndex  int
+	field      string
+	message    string
+}
+
+// ruleFindings returns the findings about single rules or groups of files
+// of the lint categories enabled by lintSettings.
+func ruleFindings(files []ruleFile, lintSettings lintConfig) []ruleFinding {
+	var findings []ruleFinding
+	if lintSettings.lintAlertPolicy() {
+		findings = append(findings, checkAlertPolicy(files, lintSettings.alertPolicy)...)
+	}
+	if lintSettings.lintRecordingRuleNames() {
+		findings = append(findings, checkRecordingRuleNames(files)...)
+	}
+	if lintSettings.lintPromQLAntiPatterns() {
+		findings = append(findings, checkPromQLAntiPatterns(files)...)
+	}
+	return findings
+}
+
+// lintRuleGroups returns a Diagnostic for each lint finding in files. The
+// rule groups of all the files are linted together.
+func lintRuleGroups(files []ruleFile, lintSettings lintConfig) []Diagnostic {
+	var diags []Diagnostic
+	if lintSettings.lintDuplicateRules() {
+		for _, n := range checkDuplicates(files) {
+			positions := files[n.fileIndex].positions
+			d := Diagnostic{
+				File:    files[n.fileIndex].name,
+				Group:   n.group,
+				Rule:    n.metric,
+				Kind:    DiagnosticKindLint,
+				Message: fmt.Sprintf("duplicate rule %s%s, might cause inconsistency while recording expressions", n.metric, n.label.String()),
+			}
+			if n.groupIndex < len(positions) && n.ruleIndex < len(positions[n.groupIndex]) {
+				d.Line = positions[n.groupIndex][n.ruleIndex].Line
+				d.Column = positions[n.groupIndex][n.ruleIndex].Column
+			}
+			diags = append(diags, d)
+		}
+	}
+	if lintSettings.lintDuplicateGroups() {
+		for _, g := range checkDuplicateGroups(files) {
+			file := files[g.fileIndex]
+			d := Diagnostic{
+				File:    file.name,
+				Group:   g.name,
+				Kind:    DiagnosticKindLint,
+				Message: fmt.Sprintf("group name %q is already used in %s", g.name, files[g.firstFileIndex].name),
+			}
+			if g.groupIndex < len(file.groupPositions) {
+				d.Line = file.groupPositions[g.groupIndex].Line
+				d.Column = file.groupPositions[g.groupIndex].Column
+			}
+			diags = append(diags, d)
+		}
+	}
+	for _, f := range ruleFindings(files, lintSettings) {
+		file := files[f.fileIndex]
+		group := file.groups.Groups[f.groupIndex]
+		d := Diagnostic{
+			File:    file.name,
+			Group:   group.Name,
+			Kind:    DiagnosticKindLint,
+			Message: f.message,
+		}
+		var node *yaml.Node
+		if f.ruleIndex < 0 {
+			if f.groupIndex < len(file.groupPositions) {
+				node = &file.groupPositions[f.groupIndex]
+			}
+		} else {
+			d.Rule = ruleMetric(group.Rules[f.ruleIndex])
+			d.ruleIndex = f.ruleIndex + 1
+			d.groupIndex = f.groupIndex
+			if f.groupIndex < len(file.positions) && f.ruleIndex < len(file.positions[f.groupIndex]) {
+				node = &file.positions[f.groupIndex][f.ruleIndex]
+				if _, value := mappingValue(node, f.field); value != nil {
+					node = value
+				}
+			}
+		}
+		if node != nil {
+			d.Line, d.Column = node.Line, node.Column
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// rulePositions returns the YAML node of every group in content, and of
+// every rule indexed by group and rule, so that findings can be attributed
+// a line and column.
+func rulePositions(content []byte) ([]yaml.Node, [][]yaml.Node) {
+	var doc struct {
+		Groups []yaml.Node `yaml:"groups"`
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil
+	}
+
+	positions := make([][]yaml.Node, 0, len(doc.Groups))
+	for _, g := range doc.Groups {
+		var group struct {
+			Rules []yaml.Node `yaml:"rules"`
+		}
+		if err := g.Decode(&group); err != nil {
+			return nil, nil
+		}
+		positions = append(positions, group.Rules)
+	}
+	return doc.Groups, positions
+}
+
+// duplicateGroup is a group whose name is already used by a group of
+// another file.
+type duplicateGroup struct {
+	name           string
+	fileIndex      int
+	groupIndex     int
+	firstFileIndex int
+}
+
+// checkDuplicateGroups returns the groups of files whose name is used by a
+// group of a previous file. Duplicate names inside a single file are
+// already rejected by rulefmt.
+func checkDuplicateGroups(files []ruleFile) []duplicateGroup {
+	var duplicates []duplicateGroup
+	seen := map[string]int{}
+	for f, file := range files {
+		for i, group := range file.groups.Groups {
+			first, ok := seen[group.Name]
+			if !ok {
+				seen[group.Name] = f
+				continue
+			}
+			if first != f {
+				duplicates = append(duplicates, duplicateGroup{
+					name:           group.Name,
+					fileIndex:      f,
+					groupIndex:     i,
+					firstFileIndex: first,
+				})
+			}
+		}
+	}
+	return duplicates
+}
+
 type compareRuleType struct {
 	metric string
 	label  labels.Labels
+
+	group      string
+	fileIndex  int
+	groupIndex int
+	ruleIndex  int
 }
 
 type compareRuleTypes []compareRuleType
@@ -123,22 +591,30 @@ func ruleMetric(rule rulefmt.Rule) string {
 	return rule.Record
 }
 
-func checkDuplicates(groups []rulefmt.RuleGroup) []compareRuleType {
+func checkDuplicates(files []ruleFile) []compareRuleType {
 	var duplicates []compareRuleType
 	var rules compareRuleTypes
 
-	for _, group := range groups {
-		for _, rule := range group.Rules {
-			rules = append(rules, compareRuleType{
-				metric: ruleMetric(rule),
-				label:  labels.FromMap(rule.Labels),
-			})
+	for f, file := range files {
+		for i, group := range file.groups.Groups {
+			for j, rule := range group.Rules {
+				rules = append(rules, compareRuleType{
+					metric:     ruleMetric(rule),
+					label:      labels.FromMap(rule.Labels),
+					group:      group.Name,
+					fileIndex:  f,
+					groupIndex: i,
+					ruleIndex:  j,
+				})
+			}
 		}
 	}
 	if len(rules) < 2 {
 		return duplicates
 	}
-	sort.Sort(rules)
+	// The sort is stable so that the first rule of a duplicate is the one
+	// found first, in file order, and the next one is blamed.
+	sort.Stable(rules)
 
 	last := rules[0]
 	for i := 1; i < len(rules); i++ {
@@ -0,0 +1,65 @@
+package promtool
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCheckDuplicatesAcrossFiles(t *testing.T) {
+	const rules = `
+groups:
+- name: example
+  rules:
+  - record: job:http_requests:rate5m
+    expr: sum by (job) (rate(http_requests_total[5m]))
+  - record: job:http_errors:rate5m
+    expr: sum by (job) (rate(http_errors_total[5m]))
+`
+	// Enough files for the sort not to fall back to an insertion sort,
+	// which would keep the order of the duplicates anyway.
+	var files []ruleFile
+	for i := 0; i < 20; i++ {
+		f, errs := parseRuleFile(fmt.Sprintf("rules_%d.yml", i), []byte(rules))
+		if len(errs) != 0 {
+			t.Fatal(errs)
+		}
+		files = append(files, f)
+	}
+
+	// The second file is blamed, the first one holding the original rules.
+	var got []string
+	for _, d := range checkDuplicates(files) {
+		got = append(got, fmt.Sprintf("%s %s rules[%d]", files[d.fileIndex].name, d.metric, d.ruleIndex))
+	}
+	want := []string{
+		"rules_1.yml job:http_errors:rate5m rules[1]",
+		"rules_1.yml job:http_requests:rate5m rules[0]",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("checkDuplicates() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateRuleFilesRepeatedGroup(t *testing.T) {
+	const rules = `
+groups:
+- name: example
+  rules:
+  - record: job:up:sum
+    expr: sum by (job) (up)
+- name: example
+  rules:
+  - record: job:up:sum
+    expr: sum by (job) (up)
+`
+	errs, _ := ValidateRuleFiles(map[string]string{"rules.yml": rules}, RulesOptions{})
+
+	var got []string
+	for _, d := range errs {
+		got = append(got, fmt.Sprintf("%s:%d:%d %s: %s", d.File, d.Line, d.Column, d.Path, d.Message))
+	}
+	want := []string{`rules.yml:7:3 groups[1]: groupname: "example" is repeated in the same file`}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ValidateRuleFiles() = %q, want %q", got, want)
+	}
+}
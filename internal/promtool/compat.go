@@ -0,0 +1,428 @@
+package promtool
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is a Prometheus release, e.g. 2.53.0. The zero Version means that
+// no version is targeted.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+var versionRegexp = regexp.MustCompile(`^v?(\d+)\.(\d+)(?:\.(\d+))?(?:[-+].*)?$`)
+
+// ParseVersion parses a Prometheus version such as 2.53, 2.53.1 or
+// v3.0.0-rc.0. Pre-release and build suffixes are ignored.
+func ParseVersion(s string) (Version, error) {
+	m := versionRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid Prometheus version %q", s)
+	}
+	var v Version
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	return v, nil
+}
+
+// parseTargetVersion parses s like ParseVersion does, the zero Version is
+// returned when s is empty.
+func parseTargetVersion(s string) (Version, error) {
+	if s == "" {
+		return Version{}, nil
+	}
+	return ParseVersion(s)
+}
+
+func (v Version) IsZero() bool {
+	return v == Version{}
+}
+
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+const (
+	compatKindConfigField = "configuration field"
+	compatKindRuleField   = "rule field"
+	compatKindFunction    = "PromQL function"
+)
+
+// compatEntry records when a feature appeared in, was deprecated in or was
+// removed from Prometheus. Fields are identified by their YAML path, where a
+// [] suffix stands for every element of a sequence, functions by their name.
+type compatEntry struct {
+	kind       string
+	name       string
+	introduced Version
+	deprecated Version
+	removed    Version
+	note       string
+}
+
+// compatTable lists the features whose support depends on the version of
+// Prometheus. Features supported by every version still in use are left
+// out.
+var compatTable = []compatEntry{
+	// Configuration.
+	{kind: compatKindConfigField, name: "global.rule_query_offset", introduced: Version{2, 53, 0}},
+	{kind: compatKindConfigField, name: "global.scrape_protocols", introduced: Version{2, 49, 0}},
+	{kind: compatKindConfigField, name: "global.metric_name_validation_scheme", introduced: Version{3, 0, 0}},
+	{kind: compatKindConfigField, name: "global.always_scrape_classic_histograms", introduced: Version{3, 0, 0}},
+	{kind: compatKindConfigField, name: "global.convert_classic_histograms_to_nhcb", introduced: Version{3, 0, 0}},
+	{kind: compatKindConfigField, name: "scrape_configs[].scrape_protocols", introduced: Version{2, 49, 0}},
+	{kind: compatKindConfigField, name: "scrape_configs[].enable_compression", introduced: Version{2, 49, 0}},
+	{kind: compatKindConfigField, name: "scrape_configs[].keep_dropped_targets", introduced: Version{2, 47, 0}},
+	{kind: compatKindConfigField, name: "scrape_configs[].track_timestamps_staleness", introduced: Version{2, 48, 0}},
+	{kind: compatKindConfigField, name: "scrape_configs[].native_histogram_bucket_limit", introduced: Version{2, 45, 0}},
+	{kind: compatKindConfigField, name: "scrape_configs[].metric_name_validation_scheme", introduced: Version{3, 0, 0}},
+	{kind: compatKindConfigField, name: "scrape_configs[].always_scrape_classic_histograms", introduced: Version{3, 0, 0}},
+	{kind: compatKindConfigField, name: "scrape_configs[].convert_classic_histograms_to_nhcb", introduced: Version{3, 0, 0}},
+	{kind: compatKindConfigField, name: "scrape_configs[].authorization", introduced: Version{2, 26, 0}},
+	{kind: compatKindConfigField, name: "scrape_configs[].bearer_token", deprecated: Version{2, 26, 0}, note: "use authorization.credentials instead"},
+	{kind: compatKindConfigField, name: "scrape_configs[].bearer_token_file", deprecated: Version{2, 26, 0}, note: "use authorization.credentials_file instead"},
+	{kind: compatKindConfigField, name: "storage.tsdb.out_of_order_time_window", introduced: Version{2, 39, 0}},
+
+	// Rules.
+	{kind: compatKindRuleField, name: "groups[].limit", introduced: Version{2, 31, 0}},
+	{kind: compatKindRuleField, name: "groups[].query_offset", introduced: Version{2, 53, 0}},
+	{kind: compatKindRuleField, name: "groups[].labels", introduced: Version{3, 0, 0}},
+	{kind: compatKindRuleField, name: "groups[].rules[].keep_firing_for", introduced: Version{2, 42, 0}},
+
+	// PromQL.
+	{kind: compatKindFunction, name: "absent_over_time", introduced: Version{2, 16, 0}},
+	{kind: compatKindFunction, name: "last_over_time", introduced: Version{2, 26, 0}},
+	{kind: compatKindFunction, name: "clamp", introduced: Version{2, 26, 0}},
+	{kind: compatKindFunction, name: "present_over_time", introduced: Version{2, 29, 0}},
+	{kind: compatKindFunction, name: "histogram_count", introduced: Version{2, 40, 0}},
+	{kind: compatKindFunction, name: "histogram_sum", introduced: Version{2, 40, 0}},
+	{kind: compatKindFunction, name: "histogram_fraction", introduced: Version{2, 40, 0}},
+	{kind: compatKindFunction, name: "sort_by_label", introduced: Version{2, 49, 0}},
+	{kind: compatKindFunction, name: "sort_by_label_desc", introduced: Version{2, 49, 0}},
+	{kind: compatKindFunction, name: "histogram_avg", introduced: Version{2, 53, 0}},
+	{kind: compatKindFunction, name: "double_exponential_smoothing", introduced: Version{3, 0, 0}, note: "use holt_winters with older versions"},
+	{kind: compatKindFunction, name: "holt_winters", removed: Version{3, 0, 0}, note: "use double_exponential_smoothing instead"},
+	{kind: compatKindFunction, name: "info", introduced: Version{3, 0, 0}},
+}
+
+// check returns the error raised by using e with target when e is not
+// supported by it, or the warning raised when e is deprecated. name is how
+// the feature is named in the messages, e.g. the concrete path of a field.
+func (e compatEntry) check(target Version, name string) (err, warning error) {
+	var msg string
+	deprecated := false
+	switch {
+	case !e.introduced.IsZero() && target.Less(e.introduced):
+		msg = fmt.Sprintf("%s %s is not supported by Prometheus %s, it was introduced in %s", e.kind, name, target, e.introduced)
+	case !e.removed.IsZero() && !target.Less(e.removed):
+		msg = fmt.Sprintf("%s %s is not supported by Prometheus %s, it was removed in %s", e.kind, name, target, e.removed)
+	case !e.deprecated.IsZero() && !target.Less(e.deprecated):
+		msg = fmt.Sprintf("%s %s is deprecated since Prometheus %s", e.kind, name, e.deprecated)
+		deprecated = true
+	default:
+		return nil, nil
+	}
+
+	if e.note != "" {
+		msg += ", " + e.note
+	}
+	if deprecated {
+		return nil, errors.New(msg)
+	}
+	return errors.New(msg), nil
+}
+
+// yamlMatch is a node found by findYAMLPath. path is the concrete path of
+// the node and indexes the index of every sequence element it goes through.
+// key is the node holding the position of the match: the mapping key, or
+// the element itself for sequence elements.
+type yamlMatch struct {
+	path    string
+	indexes []int
+	node    *yaml.Node
+	key     *yaml.Node
+}
+
+// findYAMLPath returns the nodes of the document root found at path, a dot
+// separated list of keys where a [] suffix iterates over the elements of a
+// sequence.
+func findYAMLPath(root *yaml.Node, path string) []yamlMatch {
+	if root.Kind == yaml.DocumentNode {
+		if len(root.Content) == 0 {
+			return nil
+		}
+		root = root.Content[0]
+	}
+
+	matches := []yamlMatch{{node: root}}
+	for _, segment := range strings.Split(path, ".") {
+		key, iterate := strings.CutSuffix(segment, "[]")
+
+		var next []yamlMatch
+		for _, m := range matches {
+			keyNode, value := mappingValue(m.node, key)
+			if value == nil {
+				continue
+			}
+			p := key
+			if m.path != "" {
+				p = m.path + "." + key
+			}
+			if !iterate {
+				next = append(next, yamlMatch{path: p, indexes: m.indexes, node: value, key: keyNode})
+				continue
+			}
+			if value.Kind != yaml.SequenceNode {
+				continue
+			}
+			for i, elem := range value.Content {
+				next = append(next, yamlMatch{
+					path:    fmt.Sprintf("%s[%d]", p, i),
+					indexes: append(append([]int{}, m.indexes...), i),
+					node:    elem,
+					key:     elem,
+				})
+			}
+		}
+		matches = next
+	}
+	return matches
+}
+
+// yamlPathAt returns the path, in the format of findYAMLPath matches, of the
+// outermost node of the document root found at line and column, or at line
+// when column is zero. Keys stand for the field they introduce. It returns
+// an empty string when there is no such node.
+func yamlPathAt(root *yaml.Node, line, column int) string {
+	if root.Kind == yaml.DocumentNode {
+		if len(root.Content) == 0 {
+			return ""
+		}
+		root = root.Content[0]
+	}
+
+	at := func(n *yaml.Node) bool {
+		return n.Line == line && (column == 0 || n.Column == column)
+	}
+
+	var walk func(n *yaml.Node, path string) (string, bool)
+	walk = func(n *yaml.Node, path string) (string, bool) {
+		if path != "" && at(n) {
+			return path, true
+		}
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				p := n.Content[i].Value
+				if path != "" {
+					p = path + "." + p
+				}
+				if at(n.Content[i]) {
+					return p, true
+				}
+				if p, ok := walk(n.Content[i+1], p); ok {
+					return p, true
+				}
+			}
+		case yaml.SequenceNode:
+			for i, elem := range n.Content {
+				if p, ok := walk(elem, fmt.Sprintf("%s[%d]", path, i)); ok {
+					return p, true
+				}
+			}
+		}
+		return "", false
+	}
+
+	p, _ := walk(root, "")
+	return p
+}
+
+// mappingValue returns the key and value nodes of key in the mapping node,
+// or nils when node is not a mapping or does not hold key.
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// compatFunctions are the functions known when looking for the functions
+// used by an expression. Experimental functions are enabled, and the
+// functions removed from Prometheus are added back, so that every function
+// of the compatibility table can be found.
+var compatFunctions = func() map[string]*parser.Function {
+	functions := make(map[string]*parser.Function, len(parser.Functions)+1)
+	for name, f := range parser.Functions {
+		f := *f
+		f.Experimental = false
+		functions[name] = &f
+	}
+	functions[holtWinters.Name] = holtWinters
+	return functions
+}()
+
+// holtWinters is the holt_winters function of Prometheus 2, renamed to
+// double_exponential_smoothing in Prometheus 3.
+var holtWinters = func() *parser.Function {
+	f := *parser.Functions["double_exponential_smoothing"]
+	f.Name = "holt_winters"
+	f.Experimental = false
+	return &f
+}()
+
+// exprFunctions returns the names of the functions called by expr, or nil
+// when expr cannot be parsed.
+func exprFunctions(expr string) []string {
+	p := parser.NewParser(expr, parser.WithFunctions(compatFunctions))
+	defer p.Close()
+
+	e, err := p.ParseExpr()
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	seen := map[string]struct{}{}
+	parser.Inspect(e, func(node parser.Node, _ []parser.Node) error {
+		if call, ok := node.(*parser.Call); ok {
+			if _, ok := seen[call.Func.Name]; !ok {
+				seen[call.Func.Name] = struct{}{}
+				names = append(names, call.Func.Name)
+			}
+		}
+		return nil
+	})
+	return names
+}
+
+// checkRulesCompatibility returns the errors and warnings raised by using
+// the rule fields and PromQL functions found in files with target.
+func checkRulesCompatibility(files []ruleFile, target Version) (errs, warnings []Diagnostic) {
+	if target.IsZero() {
+		return nil, nil
+	}
+
+	add := func(d Diagnostic, err, warning error) {
+		d.Kind = DiagnosticKindCompatibility
+		if err != nil {
+			d.Message = err.Error()
+			errs = append(errs, d)
+		}
+		if warning != nil {
+			d.Message = warning.Error()
+			warnings = append(warnings, d)
+		}
+	}
+
+	functions := map[string]compatEntry{}
+	for _, e := range compatTable {
+		if e.kind == compatKindFunction {
+			functions[e.name] = e
+		}
+	}
+
+	for _, f := range files {
+		for _, e := range compatTable {
+			if e.kind != compatKindRuleField {
+				continue
+			}
+			for _, m := range findYAMLPath(&f.doc, e.name) {
+				d := Diagnostic{File: f.name, Line: m.key.Line, Column: m.key.Column}
+				if len(m.indexes) > 0 && m.indexes[0] < len(f.groups.Groups) {
+					group := f.groups.Groups[m.indexes[0]]
+					d.Group = group.Name
+					if len(m.indexes) > 1 && m.indexes[1] < len(group.Rules) {
+						d.Rule = ruleMetric(group.Rules[m.indexes[1]])
+					}
+				}
+				err, warning := e.check(target, m.path)
+				add(d, err, warning)
+			}
+		}
+
+		for i, group := range f.groups.Groups {
+			for j, rule := range group.Rules {
+				d := Diagnostic{File: f.name, Group: group.Name, Rule: ruleMetric(rule)}
+				if i < len(f.positions) && j < len(f.positions[i]) {
+					d.Line = f.positions[i][j].Line
+					d.Column = f.positions[i][j].Column
+				}
+				for _, name := range exprFunctions(rule.Expr) {
+					if e, ok := functions[name]; ok {
+						err, warning := e.check(target, name)
+						add(d, err, warning)
+					}
+				}
+			}
+		}
+	}
+	return errs, warnings
+}
+
+// checkConfigCompatibility returns the errors and warnings raised by using
+// the fields of the configuration content with target.
+func checkConfigCompatibility(content string, target Version) (errs, warnings []ConfigDiagnostic) {
+	if target.IsZero() {
+		return nil, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, nil
+	}
+
+	for _, e := range compatTable {
+		if e.kind != compatKindConfigField {
+			continue
+		}
+		for _, m := range findYAMLPath(&doc, e.name) {
+			err, warning := e.check(target, m.path)
+			if err != nil {
+				errs = append(errs, ConfigDiagnostic{Section: configSection(m.path), Name: m.path, Err: err})
+			}
+			if warning != nil {
+				warnings = append(warnings, ConfigDiagnostic{Section: configSection(m.path), Name: m.path, Err: warning})
+			}
+		}
+	}
+	return errs, warnings
+}
+
+// configSection returns the section of the configuration holding path.
+func configSection(path string) string {
+	section := path
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		section = path[:i]
+	}
+	switch section {
+	case ConfigSectionScrapeConfigs, ConfigSectionRuleFiles, ConfigSectionAlerting:
+		return section
+	}
+	return ConfigSectionConfig
+}
@@ -19,7 +19,15 @@ const (
 	successExitCode = 0
 	failureExitCode = 1
 
-	lintOptionAll            = "all"
-	lintOptionDuplicateRules = "duplicate-rules"
-	lintOptionNone           = "none"
+	lintOptionAll             = "all"
+	lintOptionDuplicateRules  = "duplicate-rules"
+	lintOptionDuplicateGroups = "duplicate-groups"
+	lintOptionAlertPolicy     = "alert-policy"
+	// lintOptionRecordingRuleNames enforces a naming convention, it is not
+	// enabled by lintOptionAll.
+	lintOptionRecordingRuleNames = "recording-rule-names"
+	// lintOptionPromQLAntiPatterns relies on heuristics, it is not enabled
+	// by lintOptionAll.
+	lintOptionPromQLAntiPatterns = "promql-anti-patterns"
+	lintOptionNone               = "none"
 )
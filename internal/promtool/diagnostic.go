@@ -0,0 +1,126 @@
+package promtool
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DiagnosticKindYAML       = "yaml"
+	DiagnosticKindValidation = "validation"
+	DiagnosticKindLint       = "lint"
+	// DiagnosticKindCompatibility is used for the features not supported
+	// by, or deprecated in, the targeted Prometheus version.
+	DiagnosticKindCompatibility = "compatibility"
+)
+
+// Diagnostic describes a single problem found while checking a rules file.
+// File is empty when the rules were not read from a file, Line and Column
+// are zero when the position is unknown. Path is the field at that
+// position, e.g. groups[2].rules[0].expr, it is empty when unknown.
+type Diagnostic struct {
+	File    string
+	Group   string
+	Rule    string
+	Line    int
+	Column  int
+	Path    string
+	Kind    string
+	Message string
+
+	// ruleIndex is the 1-based index of the rule in its group given by
+	// rulefmt, and groupIndex the index of that group in the file. They are
+	// used to find Path when the position is unknown.
+	ruleIndex  int
+	groupIndex int
+}
+
+func (d Diagnostic) Error() string {
+	return d.Message
+}
+
+// Position returns the location of the problem as file:line:column, leaving
+// out the parts that are unknown.
+func (d Diagnostic) Position() string {
+	var parts []string
+	if d.File != "" {
+		parts = append(parts, d.File)
+	}
+	if d.Line != 0 {
+		parts = append(parts, strconv.Itoa(d.Line))
+		if d.Column != 0 {
+			parts = append(parts, strconv.Itoa(d.Column))
+		}
+	}
+	return strings.Join(parts, ":")
+}
+
+var (
+	positionPrefix = regexp.MustCompile(`^(\d+):(\d+): (?:\d+:\d+: )?`)
+	yamlLine       = regexp.MustCompile(`line (\d+)`)
+)
+
+// newRuleFileDiagnostic converts an error returned by rulefmt.Parse into a
+// Diagnostic, recovering the position and the rule from the error text
+// since rulefmt does not expose them otherwise.
+func newRuleFileDiagnostic(err error) Diagnostic {
+	d := Diagnostic{
+		Kind:    DiagnosticKindValidation,
+		Message: err.Error(),
+	}
+
+	var ruleErr *rulefmt.Error
+	if errors.As(err, &ruleErr) {
+		d.Group = ruleErr.Group
+		d.Rule = ruleErr.RuleName
+		d.ruleIndex = ruleErr.Rule
+		d.Message = ruleErr.Err.Error()
+	}
+
+	if m := positionPrefix.FindStringSubmatch(d.Message); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+		d.Column, _ = strconv.Atoi(m[2])
+		d.Message = d.Message[len(m[0]):]
+	}
+
+	return d
+}
+
+// newYAMLDiagnostics converts YAML decoding errors into Diagnostics, one per
+// reported problem. rulefmt.Parse decodes the document twice so the same
+// error may be reported more than once, duplicates are dropped.
+func newYAMLDiagnostics(errs []error) []Diagnostic {
+	var messages []string
+	for _, err := range errs {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			messages = append(messages, typeErr.Errors...)
+			continue
+		}
+		messages = append(messages, err.Error())
+	}
+
+	var diags []Diagnostic
+	seen := map[string]struct{}{}
+	for _, msg := range messages {
+		if _, ok := seen[msg]; ok {
+			continue
+		}
+		seen[msg] = struct{}{}
+
+		d := Diagnostic{
+			Kind:    DiagnosticKindYAML,
+			Message: msg,
+		}
+		if m := yamlLine.FindStringSubmatch(msg); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
@@ -0,0 +1,140 @@
+package promtool
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Feature flags, as given to the --enable-feature flag of Prometheus, that
+// change how configurations and rules are checked.
+const (
+	FeaturePromQLExperimentalFunctions = "promql-experimental-functions"
+	FeaturePromQLDurationExpr          = "promql-duration-expr"
+	FeatureNativeHistograms            = "native-histograms"
+	// FeatureUTF8Names enables UTF-8 metric and label names with
+	// Prometheus 2, Prometheus 3 always accepts them.
+	FeatureUTF8Names = "utf8-names"
+)
+
+// featureSet holds the feature flags enabled for a check.
+type featureSet struct {
+	experimentalFunctions bool
+	durationExpr          bool
+	nativeHistograms      bool
+	utf8Names             bool
+	// holtWinters registers the holt_winters function, removed in
+	// Prometheus 3, with the PromQL parser.
+	holtWinters bool
+}
+
+// parseFeatureFlags returns the featureSet enabled by flags. Every flag may
+// hold a comma separated list of features, like --enable-feature does.
+// Unknown features are ignored and returned as warnings.
+func parseFeatureFlags(flags []string) (featureSet, []error) {
+	var fs featureSet
+	var warnings []error
+	for _, flag := range flags {
+		for _, f := range strings.Split(flag, ",") {
+			switch strings.TrimSpace(f) {
+			case FeaturePromQLExperimentalFunctions:
+				fs.experimentalFunctions = true
+			case FeaturePromQLDurationExpr:
+				fs.durationExpr = true
+			case FeatureNativeHistograms:
+				fs.nativeHistograms = true
+			case FeatureUTF8Names:
+				fs.utf8Names = true
+			case "":
+			default:
+				warnings = append(warnings, fmt.Errorf("unknown feature flag %s", f))
+			}
+		}
+	}
+	return fs, warnings
+}
+
+// nameValidationScheme returns how metric and label names are validated by
+// target with the features of fs. Prometheus 2 only accepts legacy names
+// unless utf8-names is enabled.
+func (fs featureSet) nameValidationScheme(target Version) model.ValidationScheme {
+	if !target.IsZero() && target.Major < 3 && !fs.utf8Names {
+		return model.LegacyValidation
+	}
+	return model.UTF8Validation
+}
+
+// forVersion returns fs with the features of the PromQL parser that target
+// needs, such as the holt_winters function of Prometheus 2.
+func (fs featureSet) forVersion(target Version) featureSet {
+	fs.holtWinters = !target.IsZero() && target.Major < 3
+	return fs
+}
+
+// featuresMu serializes the checks, the PromQL parser and the name
+// validation scheme being configured through package variables.
+var featuresMu sync.Mutex
+
+// useFeatures configures the PromQL parser for fs and the name validation
+// scheme to scheme until the returned function is called. No other check
+// can run in the meantime.
+func useFeatures(fs featureSet, scheme model.ValidationScheme) func() {
+	featuresMu.Lock()
+
+	experimentalFunctions := parser.EnableExperimentalFunctions
+	durationExpr := parser.ExperimentalDurationExpr
+	nameValidationScheme := model.NameValidationScheme
+
+	parser.EnableExperimentalFunctions = fs.experimentalFunctions
+	parser.ExperimentalDurationExpr = fs.durationExpr
+	model.NameValidationScheme = scheme
+	if fs.holtWinters {
+		parser.Functions[holtWinters.Name] = holtWinters
+	}
+
+	return func() {
+		parser.EnableExperimentalFunctions = experimentalFunctions
+		parser.ExperimentalDurationExpr = durationExpr
+		model.NameValidationScheme = nameValidationScheme
+		if fs.holtWinters {
+			delete(parser.Functions, holtWinters.Name)
+		}
+		featuresMu.Unlock()
+	}
+}
+
+// nativeHistogramFields are the configuration fields that have no effect
+// unless native histograms are enabled.
+var nativeHistogramFields = []string{
+	"scrape_configs[].native_histogram_bucket_limit",
+	"scrape_configs[].native_histogram_min_bucket_factor",
+}
+
+// checkConfigFeatures returns warnings for the fields of the configuration
+// content that have no effect without a feature that fs does not enable.
+func checkConfigFeatures(content string, fs featureSet) []ConfigDiagnostic {
+	if fs.nativeHistograms {
+		return nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil
+	}
+
+	var warnings []ConfigDiagnostic
+	for _, path := range nativeHistogramFields {
+		for _, m := range findYAMLPath(&doc, path) {
+			warnings = append(warnings, ConfigDiagnostic{
+				Section: configSection(m.path),
+				Name:    m.path,
+				Err:     fmt.Errorf("%s has no effect unless the %s feature is enabled", m.path, FeatureNativeHistograms),
+			})
+		}
+	}
+	return warnings
+}
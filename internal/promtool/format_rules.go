@@ -0,0 +1,111 @@
+package promtool
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// FormatRules parses the rules content like CheckRules does and returns it
+// in canonical form: the fields of groups and rules in the order Prometheus
+// defines them, labels and annotations sorted, and expressions
+// pretty-printed like FormatPromQL does. The comments of content are kept
+// on the fields they are attached to. Unknown feature flags are returned as
+// warnings, and the problems found while parsing as errors.
+func FormatRules(content string, opts FormatOptions) (string, []error, []error) {
+	features, warnings := parseFeatureFlags(opts.FeatureFlags)
+	defer useFeatures(features, model.UTF8Validation)()
+
+	if opts.MaxWidth < 0 {
+		return "", warnings, []error{fmt.Errorf("invalid maximum width %d", opts.MaxWidth)}
+	}
+
+	rgs, errs := rulefmt.Parse([]byte(content), false)
+	if len(errs) > 0 {
+		return "", warnings, errs
+	}
+
+	for i := range rgs.Groups {
+		for j := range rgs.Groups[i].Rules {
+			rule := &rgs.Groups[i].Rules[j]
+			expr, err := parser.ParseExpr(rule.Expr)
+			if err != nil {
+				// Already reported by rulefmt.Parse.
+				continue
+			}
+			rule.Expr = prettify(expr, opts.MaxWidth)
+		}
+	}
+
+	out, err := encodeYAML(rgs)
+	if err != nil {
+		return "", warnings, []error{err}
+	}
+
+	// The comments are copied to the node tree of the formatted rules.
+	// yaml.Node.Encode is not used to build it, it uses an indentation the
+	// block scalars of the expressions cannot always be decoded with.
+	var doc, formatted yaml.Node
+	if yaml.Unmarshal([]byte(content), &doc) != nil || yaml.Unmarshal([]byte(out), &formatted) != nil {
+		return out, warnings, nil
+	}
+	copyComments(&formatted, &doc)
+	out, err = encodeYAML(&formatted)
+	if err != nil {
+		return "", warnings, []error{err}
+	}
+	return out, warnings, nil
+}
+
+// copyComments copies the comments of the src node tree to the matching
+// nodes of dst: mapping values are matched by key and sequence items by
+// index.
+func copyComments(dst, src *yaml.Node) {
+	dst.HeadComment = src.HeadComment
+	dst.LineComment = src.LineComment
+	dst.FootComment = src.FootComment
+	if dst.Kind != src.Kind {
+		return
+	}
+
+	switch src.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for i := 0; i < len(src.Content) && i < len(dst.Content); i++ {
+			copyComments(dst.Content[i], src.Content[i])
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := mappingValue(dst, src.Content[i].Value)
+			if key == nil {
+				continue
+			}
+			copyComments(key, src.Content[i])
+			copyComments(value, src.Content[i+1])
+		}
+	}
+}
+
+// EncodeRules returns rgs as a YAML rules document, with the fields of
+// groups and rules in the order Prometheus defines them and labels and
+// annotations sorted.
+func EncodeRules(rgs *rulefmt.RuleGroups) (string, error) {
+	return encodeYAML(rgs)
+}
+
+// encodeYAML returns v as a YAML document indented with two spaces.
+func encodeYAML(v any) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
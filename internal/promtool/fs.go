@@ -0,0 +1,68 @@
+package promtool
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FileSystem gives the checkers access to the files referenced by a
+// configuration, such as rule files, service discovery files or TLS
+// certificates.
+type FileSystem interface {
+	Glob(pattern string) ([]string, error)
+	Stat(name string) error
+	ReadFile(name string) ([]byte, error)
+}
+
+// OSFileSystem is the FileSystem backed by the local filesystem.
+type OSFileSystem struct{}
+
+func (OSFileSystem) Glob(pattern string) ([]string, error) { return filepath.Glob(pattern) }
+
+func (OSFileSystem) Stat(name string) error {
+	_, err := os.Stat(name)
+	return err
+}
+
+func (OSFileSystem) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+// MapFileSystem is a FileSystem serving files from memory, keyed by path.
+// Globs are matched against the keys using filepath.Match.
+type MapFileSystem map[string]string
+
+func (m MapFileSystem) Glob(pattern string) ([]string, error) {
+	// Check the pattern once so that a bad pattern is reported even
+	// when there are no files.
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for name := range m {
+		name = filepath.Clean(name)
+		if ok, _ := filepath.Match(filepath.Clean(pattern), name); ok {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func (m MapFileSystem) Stat(name string) error {
+	_, err := m.ReadFile(name)
+	if err != nil {
+		return &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return nil
+}
+
+func (m MapFileSystem) ReadFile(name string) ([]byte, error) {
+	for k, v := range m {
+		if filepath.Clean(k) == filepath.Clean(name) {
+			return []byte(v), nil
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
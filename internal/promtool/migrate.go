@@ -0,0 +1,233 @@
+package promtool
+
+import (
+	"bytes"
+	"fmt"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/common/promslog"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// MigrationChange describes a change made while migrating a document to
+// Prometheus 3, or a problem that has to be fixed by hand. Path is the YAML
+// path of the field involved, e.g. groups[0].rules[1].expr.
+type MigrationChange struct {
+	Path    string
+	Message string
+}
+
+// MigrationResult is the outcome of a migration. Content is the migrated
+// document, it is the input unchanged when there is nothing to rewrite.
+// Changes lists the changes made and Findings the problems left to be fixed
+// by hand.
+type MigrationResult struct {
+	Content  string
+	Changes  []MigrationChange
+	Findings []MigrationChange
+}
+
+func (r *MigrationResult) change(path, format string, args ...any) {
+	r.Changes = append(r.Changes, MigrationChange{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *MigrationResult) finding(path, format string, args ...any) {
+	r.Findings = append(r.Findings, MigrationChange{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// MigrateConfig rewrites the Prometheus 2 configuration content for
+// Prometheus 3. The migrated configuration is then loaded like CheckConfig
+// does with the features of opts, the problems it still has are reported as
+// findings. Unknown feature flags are returned as warnings. An error is
+// only returned when content is not a YAML document.
+func MigrateConfig(content string, opts PromQLOptions) (MigrationResult, []error, error) {
+	features, warnings := parseFeatureFlags(opts.FeatureFlags)
+	defer useFeatures(features, model.UTF8Validation)()
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return MigrationResult{}, warnings, err
+	}
+
+	var res MigrationResult
+	for _, path := range []string{"global", "scrape_configs[]"} {
+		for _, m := range findYAMLPath(&doc, path) {
+			if key, _ := mappingValue(m.node, "scrape_classic_histograms"); key != nil {
+				key.Value = "always_scrape_classic_histograms"
+				res.change(m.path+".scrape_classic_histograms", "renamed to always_scrape_classic_histograms")
+			}
+		}
+	}
+
+	for _, m := range findYAMLPath(&doc, "alerting.alertmanagers[].api_version") {
+		if m.node.Value == string(config.AlertmanagerAPIVersionV1) {
+			m.node.Value = string(config.AlertmanagerAPIVersionV2)
+			res.change(m.path, "Alertmanager API v1 is no longer supported, switched to v2")
+		}
+	}
+
+	for _, m := range findYAMLPath(&doc, "global.external_labels") {
+		if m.node.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(m.node.Content); i += 2 {
+			if strings.Contains(m.node.Content[i+1].Value, "$") {
+				res.finding(m.path+"."+m.node.Content[i].Value, "environment variables in external labels are always expanded by Prometheus 3, escape $ as $$ to keep it literally")
+			}
+		}
+	}
+
+	// Whether targets return a valid Content-Type cannot be known from the
+	// configuration, so this is only reported once.
+	for _, m := range findYAMLPath(&doc, "scrape_configs[]") {
+		if key, _ := mappingValue(m.node, "fallback_scrape_protocol"); key == nil {
+			res.finding("scrape_configs", "Prometheus 3 fails to scrape targets returning an invalid or missing Content-Type, set fallback_scrape_protocol on the jobs having such targets")
+			break
+		}
+	}
+
+	var err error
+	res.Content, err = encodeMigration(content, &doc, len(res.Changes) > 0)
+	if err != nil {
+		return MigrationResult{}, warnings, err
+	}
+
+	if _, err := config.Load(res.Content, promslog.NewNopLogger()); err != nil {
+		res.finding("", "the migrated configuration is not valid: %s", err)
+	}
+	return res, warnings, nil
+}
+
+// MigrateRules rewrites the Prometheus 2 rules content for Prometheus 3.
+// The migrated rules are then parsed like CheckRules does with the features
+// of opts, the problems they still have are reported as findings.
+// Experimental PromQL functions are always accepted since the use of
+// double_exponential_smoothing is already reported. Unknown feature flags
+// are returned as warnings. An error is only returned when content is not a
+// YAML document.
+func MigrateRules(content string, opts PromQLOptions) (MigrationResult, []error, error) {
+	features, warnings := parseFeatureFlags(opts.FeatureFlags)
+	features.experimentalFunctions = true
+	defer useFeatures(features, model.UTF8Validation)()
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return MigrationResult{}, warnings, err
+	}
+
+	var res MigrationResult
+	for _, m := range findYAMLPath(&doc, "groups[].rules[].expr") {
+		p := parser.NewParser(m.node.Value, parser.WithFunctions(compatFunctions))
+		expr, err := p.ParseExpr()
+		p.Close()
+		if err != nil {
+			// Reported when validating the migrated rules.
+			continue
+		}
+
+		m.node.Value = migrateExpr(m.node.Value, expr, m.path, &res)
+	}
+
+	var err error
+	res.Content, err = encodeMigration(content, &doc, len(res.Changes) > 0)
+	if err != nil {
+		return MigrationResult{}, warnings, err
+	}
+
+	_, errs := rulefmt.Parse([]byte(res.Content), false)
+	for _, err := range errs {
+		res.finding("", "the migrated rules are not valid: %s", err)
+	}
+	return res, warnings, nil
+}
+
+// migrateExpr returns the text of expr rewritten for Prometheus 3, and
+// records the changes made and the findings in res. Functions are renamed
+// in place so that the formatting of the expression is kept.
+func migrateExpr(text string, expr parser.Expr, path string, res *MigrationResult) string {
+	var renames []int
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		switch n := node.(type) {
+		case *parser.Call:
+			if n.Func.Name == "holt_winters" {
+				renames = append(renames, int(n.PosRange.Start))
+			}
+		case *parser.VectorSelector:
+			for _, m := range n.LabelMatchers {
+				migrateMatcher(m, path, res)
+			}
+		}
+		return nil
+	})
+
+	if len(renames) == 0 {
+		return text
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(renames)))
+	for _, start := range renames {
+		text = text[:start] + "double_exponential_smoothing" + text[start+len("holt_winters"):]
+	}
+	res.change(path, "holt_winters renamed to double_exponential_smoothing")
+	res.finding(path, "double_exponential_smoothing requires --enable-feature=promql-experimental-functions")
+	return text
+}
+
+// migrateMatcher records in res the findings about the label matcher m,
+// whose semantics changed in Prometheus 3.
+func migrateMatcher(m *labels.Matcher, path string, res *MigrationResult) {
+	switch m.Type {
+	case labels.MatchRegexp, labels.MatchNotRegexp:
+		if re, err := syntax.Parse(m.Value, syntax.Perl); err == nil && matchesAnyCharNotNL(re) {
+			res.finding(path, "the . in the regular expression of %s now also matches newlines, use [^\\n] to keep the Prometheus 2 behaviour", m)
+		}
+	case labels.MatchEqual, labels.MatchNotEqual:
+		if m.Name != "le" && m.Name != "quantile" {
+			return
+		}
+		if _, err := strconv.ParseInt(m.Value, 10, 64); err == nil {
+			res.finding(path, "%s label values are normalised to floats by Prometheus 3, %s no longer matches, use %q instead", m.Name, m, m.Value+".0")
+		}
+	}
+}
+
+// matchesAnyCharNotNL returns whether re holds a . that does not match
+// newlines.
+func matchesAnyCharNotNL(re *syntax.Regexp) bool {
+	if re.Op == syntax.OpAnyCharNotNL {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if matchesAnyCharNotNL(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeMigration returns doc encoded back to YAML when it was changed, and
+// content unchanged otherwise to keep its formatting.
+func encodeMigration(content string, doc *yaml.Node, changed bool) (string, error) {
+	if !changed {
+		return content, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,198 @@
+// Lenstra - This file contains methods adapted from the Prometheus project, taking the maximum line width as a parameter. - https://github.com/prometheus/prometheus/blob/v3.5.0/promql/parser/prettier.go
+
+// Copyright 2022 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promtool
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// DefaultMaxWidth is the line width over which promtool promql format
+// splits expressions.
+const DefaultMaxWidth = 100
+
+// FormatOptions tunes how PromQL expressions are formatted.
+type FormatOptions struct {
+	// MaxWidth is the line width over which an expression is split across
+	// several lines, DefaultMaxWidth is used when it is zero.
+	MaxWidth int
+	// FeatureFlags are the features enabled with --enable-feature, e.g.
+	// promql-experimental-functions.
+	FeatureFlags []string
+}
+
+// FormatPromQL returns the PromQL expression expr pretty-printed like
+// promtool promql format does. Unknown feature flags are returned as
+// warnings.
+func FormatPromQL(expr string, opts FormatOptions) (string, []error, error) {
+	features, warnings := parseFeatureFlags(opts.FeatureFlags)
+	defer useFeatures(features, model.UTF8Validation)()
+
+	if opts.MaxWidth < 0 {
+		return "", warnings, fmt.Errorf("invalid maximum width %d", opts.MaxWidth)
+	}
+
+	node, err := parser.ParseExpr(expr)
+	if err != nil {
+		return "", warnings, err
+	}
+	return prettify(node, opts.MaxWidth), warnings, nil
+}
+
+// prettier formats PromQL expressions, splitting the nodes whose normalized
+// form is longer than width.
+type prettier struct {
+	width int
+}
+
+func prettify(n parser.Node, width int) string {
+	if width == 0 {
+		width = DefaultMaxWidth
+	}
+	return prettier{width: width}.pretty(n, 0)
+}
+
+func (p prettier) pretty(n parser.Node, level int) string {
+	switch e := n.(type) {
+	case *parser.AggregateExpr:
+		s := indent(level)
+		if !p.needsSplit(e) {
+			return s + e.String()
+		}
+
+		s += e.ShortString()
+		s += "(\n"
+
+		if e.Op.IsAggregatorWithParam() {
+			s += fmt.Sprintf("%s,\n", p.pretty(e.Param, level+1))
+		}
+		s += fmt.Sprintf("%s\n%s)", p.pretty(e.Expr, level+1), indent(level))
+		return s
+	case *parser.BinaryExpr:
+		s := indent(level)
+		if !p.needsSplit(e) {
+			return s + e.String()
+		}
+		returnBool := ""
+		if e.ReturnBool {
+			returnBool = " bool"
+		}
+
+		return fmt.Sprintf("%s\n%s%s%s%s\n%s", p.pretty(e.LHS, level+1), indent(level), e.Op, returnBool, matchingString(e), p.pretty(e.RHS, level+1))
+	case *parser.Call:
+		s := indent(level)
+		if !p.needsSplit(e) {
+			return s + e.String()
+		}
+		args := make([]string, 0, len(e.Args))
+		for _, arg := range e.Args {
+			args = append(args, p.pretty(arg, level+1))
+		}
+		return fmt.Sprintf("%s%s(\n%s\n%s)", s, e.Func.Name, strings.Join(args, ",\n"), indent(level))
+	case *parser.ParenExpr:
+		s := indent(level)
+		if !p.needsSplit(e) {
+			return s + e.String()
+		}
+		return fmt.Sprintf("%s(\n%s\n%s)", s, p.pretty(e.Expr, level+1), indent(level))
+	case *parser.StepInvariantExpr:
+		return p.pretty(e.Expr, level)
+	case *parser.SubqueryExpr:
+		if !p.needsSplit(e) {
+			return e.String()
+		}
+		return fmt.Sprintf("%s%s", p.pretty(e.Expr, level), subqueryTimeSuffix(e))
+	case *parser.UnaryExpr:
+		child := p.pretty(e.Expr, level)
+		// Remove the indent prefix from child since we attach the prefix indent before Op.
+		child = strings.TrimSpace(child)
+		return fmt.Sprintf("%s%s%s", indent(level), e.Op, child)
+	}
+	return indent(level) + n.String()
+}
+
+// needsSplit normalizes the node and then checks if the node needs any split.
+// This is necessary to remove any trailing whitespaces.
+func (p prettier) needsSplit(n parser.Node) bool {
+	if n == nil {
+		return false
+	}
+	return len(n.String()) > p.width
+}
+
+// indent adds the indentation of level n.
+func indent(n int) string {
+	return strings.Repeat("  ", n)
+}
+
+// matchingString mirrors BinaryExpr.getMatchingStr.
+func matchingString(node *parser.BinaryExpr) string {
+	matching := ""
+	vm := node.VectorMatching
+	if vm != nil && (len(vm.MatchingLabels) > 0 || vm.On) {
+		vmTag := "ignoring"
+		if vm.On {
+			vmTag = "on"
+		}
+		matching = fmt.Sprintf(" %s (%s)", vmTag, strings.Join(vm.MatchingLabels, ", "))
+
+		if vm.Card == parser.CardManyToOne || vm.Card == parser.CardOneToMany {
+			vmCard := "right"
+			if vm.Card == parser.CardManyToOne {
+				vmCard = "left"
+			}
+			matching += fmt.Sprintf(" group_%s (%s)", vmCard, strings.Join(vm.Include, ", "))
+		}
+	}
+	return matching
+}
+
+// subqueryTimeSuffix mirrors SubqueryExpr.getSubqueryTimeSuffix.
+func subqueryTimeSuffix(node *parser.SubqueryExpr) string {
+	step := ""
+	if node.Step != 0 {
+		step = model.Duration(node.Step).String()
+	} else if node.StepExpr != nil {
+		step = node.StepExpr.String()
+	}
+	offset := ""
+	switch {
+	case node.OriginalOffsetExpr != nil:
+		offset = fmt.Sprintf(" offset %s", node.OriginalOffsetExpr)
+	case node.OriginalOffset > time.Duration(0):
+		offset = fmt.Sprintf(" offset %s", model.Duration(node.OriginalOffset))
+	case node.OriginalOffset < time.Duration(0):
+		offset = fmt.Sprintf(" offset -%s", model.Duration(-node.OriginalOffset))
+	}
+	at := ""
+	switch {
+	case node.Timestamp != nil:
+		at = fmt.Sprintf(" @ %.3f", float64(*node.Timestamp)/1000.0)
+	case node.StartOrEnd == parser.START:
+		at = " @ start()"
+	case node.StartOrEnd == parser.END:
+		at = " @ end()"
+	}
+	rangeStr := model.Duration(node.Range).String()
+	if node.RangeExpr != nil {
+		rangeStr = node.RangeExpr.String()
+	}
+	return fmt.Sprintf("[%s:%s]%s%s", rangeStr, step, at, offset)
+}
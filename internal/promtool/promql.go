@@ -0,0 +1,245 @@
+// Lenstra - This file contains methods adapted from the Prometheus project. - https://github.com/prometheus/prometheus/blob/v3.5.0/web/api/v1/translate_ast.go
+
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promtool
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// PromQLOptions tunes how PromQL expressions are parsed.
+type PromQLOptions struct {
+	// FeatureFlags are the features enabled with --enable-feature, e.g.
+	// promql-experimental-functions.
+	FeatureFlags []string
+}
+
+// ParsePromQL parses the PromQL expression expr and returns its syntax tree
+// as nested maps and slices. Every node is a map whose "type" is the kind of
+// node, e.g. vector_selector or aggregation. Durations are formatted like
+// in PromQL and missing values are nil. Unknown feature flags are returned
+// as warnings. An error wrapping ErrUnsupportedNode is returned when expr
+// is valid but its syntax tree cannot be translated.
+func ParsePromQL(expr string, opts PromQLOptions) (map[string]any, []error, error) {
+	features, warnings := parseFeatureFlags(opts.FeatureFlags)
+	defer useFeatures(features, model.UTF8Validation)()
+
+	node, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, warnings, err
+	}
+	ast, err := translateAST(node)
+	return ast, warnings, err
+}
+
+// ErrUnsupportedNode is returned by ParsePromQL when the syntax tree holds
+// a kind of node it does not know.
+var ErrUnsupportedNode = errors.New("unsupported node type")
+
+// translateAST returns node as nested maps and slices, see ParsePromQL.
+func translateAST(node parser.Expr) (map[string]any, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	switch n := node.(type) {
+	case *parser.AggregateExpr:
+		expr, err := translateAST(n.Expr)
+		if err != nil {
+			return nil, err
+		}
+		var param any
+		if n.Param != nil {
+			if param, err = translateAST(n.Param); err != nil {
+				return nil, err
+			}
+		}
+		return map[string]any{
+			"type":     "aggregation",
+			"op":       n.Op.String(),
+			"expr":     expr,
+			"param":    param,
+			"grouping": stringList(n.Grouping),
+			"without":  n.Without,
+		}, nil
+	case *parser.BinaryExpr:
+		lhs, err := translateAST(n.LHS)
+		if err != nil {
+			return nil, err
+		}
+		rhs, err := translateAST(n.RHS)
+		if err != nil {
+			return nil, err
+		}
+		var matching any
+		if m := n.VectorMatching; m != nil {
+			matching = map[string]any{
+				"card":    m.Card.String(),
+				"labels":  stringList(m.MatchingLabels),
+				"on":      m.On,
+				"include": stringList(m.Include),
+			}
+		}
+		return map[string]any{
+			"type":     "binary_expr",
+			"op":       n.Op.String(),
+			"lhs":      lhs,
+			"rhs":      rhs,
+			"matching": matching,
+			"bool":     n.ReturnBool,
+		}, nil
+	case *parser.Call:
+		args := []any{}
+		for _, arg := range n.Args {
+			a, err := translateAST(arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, a)
+		}
+		return map[string]any{
+			"type":        "call",
+			"function":    n.Func.Name,
+			"args":        args,
+			"return_type": string(n.Func.ReturnType),
+		}, nil
+	case *parser.MatrixSelector:
+		vs := n.VectorSelector.(*parser.VectorSelector)
+		return map[string]any{
+			"type":         "matrix_selector",
+			"name":         vs.Name,
+			"matchers":     translateMatchers(vs.LabelMatchers),
+			"range":        formatDuration(n.Range, n.RangeExpr),
+			"offset":       formatDuration(vs.OriginalOffset, vs.OriginalOffsetExpr),
+			"timestamp":    timestamp(vs.Timestamp),
+			"start_or_end": startOrEnd(vs.StartOrEnd),
+		}, nil
+	case *parser.SubqueryExpr:
+		expr, err := translateAST(n.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"type":         "subquery",
+			"expr":         expr,
+			"range":        formatDuration(n.Range, n.RangeExpr),
+			"step":         formatDuration(n.Step, n.StepExpr),
+			"offset":       formatDuration(n.OriginalOffset, n.OriginalOffsetExpr),
+			"timestamp":    timestamp(n.Timestamp),
+			"start_or_end": startOrEnd(n.StartOrEnd),
+		}, nil
+	case *parser.NumberLiteral:
+		// A string is used since Terraform numbers cannot hold NaN or
+		// infinities.
+		return map[string]any{
+			"type":  "number_literal",
+			"value": strconv.FormatFloat(n.Val, 'f', -1, 64),
+		}, nil
+	case *parser.ParenExpr:
+		expr, err := translateAST(n.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"type": "paren_expr",
+			"expr": expr,
+		}, nil
+	case *parser.StringLiteral:
+		return map[string]any{
+			"type":  "string_literal",
+			"value": n.Val,
+		}, nil
+	case *parser.UnaryExpr:
+		expr, err := translateAST(n.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"type": "unary_expr",
+			"op":   n.Op.String(),
+			"expr": expr,
+		}, nil
+	case *parser.VectorSelector:
+		return map[string]any{
+			"type":         "vector_selector",
+			"name":         n.Name,
+			"matchers":     translateMatchers(n.LabelMatchers),
+			"offset":       formatDuration(n.OriginalOffset, n.OriginalOffsetExpr),
+			"timestamp":    timestamp(n.Timestamp),
+			"start_or_end": startOrEnd(n.StartOrEnd),
+		}, nil
+	case *parser.DurationExpr:
+		return map[string]any{
+			"type":  "duration_expr",
+			"value": n.String(),
+		}, nil
+	case *parser.StepInvariantExpr:
+		// Only added by the engine, never by the parser.
+		return translateAST(n.Expr)
+	}
+	return nil, fmt.Errorf("%w %T", ErrUnsupportedNode, node)
+}
+
+func stringList(l []string) []any {
+	res := make([]any, 0, len(l))
+	for _, s := range l {
+		res = append(res, s)
+	}
+	return res
+}
+
+func translateMatchers(in []*labels.Matcher) []any {
+	out := []any{}
+	for _, m := range in {
+		out = append(out, map[string]any{
+			"name":  m.Name,
+			"type":  m.Type.String(),
+			"value": m.Value,
+		})
+	}
+	return out
+}
+
+// formatDuration returns the duration d as written in PromQL, or the text of
+// expr when it was given as a duration expression.
+func formatDuration(d time.Duration, expr *parser.DurationExpr) string {
+	if expr != nil {
+		return expr.String()
+	}
+	return model.Duration(d).String()
+}
+
+// timestamp returns the @ modifier timestamp t in seconds, or nil when the
+// modifier is not used or is start() or end().
+func timestamp(t *int64) any {
+	if t == nil {
+		return nil
+	}
+	return float64(*t) / 1000
+}
+
+func startOrEnd(startOrEnd parser.ItemType) any {
+	if startOrEnd == 0 {
+		return nil
+	}
+	return startOrEnd.String()
+}
@@ -0,0 +1,338 @@
+package promtool
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// counterSuffixes are the suffixes of the metrics rate and increase are
+// meant for.
+var counterSuffixes = []string{"_total", "_count", "_sum", "_bucket"}
+
+// checkPromQLAntiPatterns returns the rules of files whose expression holds
+// a common PromQL mistake.
+func checkPromQLAntiPatterns(files []ruleFile) []ruleFinding {
+	var findings []ruleFinding
+	for f, file := range files {
+		for i, group := range file.groups.Groups {
+			for j, rule := range group.Rules {
+				// Invalid expressions are already reported by rulefmt.
+				expr, err := parser.ParseExpr(rule.Expr)
+				if err != nil {
+					continue
+				}
+
+				kind := "record"
+				if rule.Alert != "" {
+					kind = "alert"
+				}
+				for _, msg := range promQLAntiPatterns(expr) {
+					findings = append(findings, ruleFinding{
+						fileIndex:  f,
+						groupIndex: i,
+						ruleIndex:  j,
+						field:      "expr",
+						message:    fmt.Sprintf("%s %s: %s", kind, ruleMetric(rule), msg),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// promQLAntiPatterns returns a message for every common mistake found in
+// expr.
+func promQLAntiPatterns(expr parser.Expr) []string {
+	var msgs []string
+	parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+		switch n := node.(type) {
+		case *parser.Call:
+			switch n.Func.Name {
+			case "rate", "irate", "increase":
+				msgs = append(msgs, checkCounterFunction(n, path)...)
+			case "histogram_quantile":
+				msgs = append(msgs, checkHistogramQuantile(n)...)
+			case "absent", "absent_over_time":
+				msgs = append(msgs, checkAbsent(n)...)
+			}
+		case *parser.VectorSelector:
+			for _, m := range n.LabelMatchers {
+				if (m.Type == labels.MatchRegexp || m.Type == labels.MatchNotRegexp) && isUnanchoredRegexp(m.Value) {
+					msgs = append(msgs, fmt.Sprintf("%s is anchored by Prometheus, the leading and trailing .* make it match any value containing %s and slow it down, remove them if this is not the intent", m, strings.TrimSuffix(strings.TrimPrefix(m.Value, ".*"), ".*")))
+				}
+			}
+		case *parser.BinaryExpr:
+			if msg := checkComparison(n); msg != "" {
+				msgs = append(msgs, msg)
+			}
+		}
+		return nil
+	})
+	return msgs
+}
+
+// checkCounterFunction checks call, a call of rate, irate or increase
+// found under the nodes of path.
+func checkCounterFunction(call *parser.Call, path []parser.Node) []string {
+	var msgs []string
+	switch arg := call.Args[0].(type) {
+	case *parser.SubqueryExpr:
+		if agg := outermostAggregation(arg.Expr); agg != nil {
+			msgs = append(msgs, fmt.Sprintf("%s over the result of %s loses the counter resets of the series it aggregates, apply %s first then aggregate, e.g. %s(%s(...))", call.Func.Name, agg.Op, call.Func.Name, agg.Op, call.Func.Name))
+		}
+	case *parser.MatrixSelector:
+		vs, ok := arg.VectorSelector.(*parser.VectorSelector)
+		if !ok {
+			break
+		}
+		// Recorded series are named level:metric:operations, and native
+		// histograms have no suffix.
+		if vs.Name != "" && !strings.Contains(vs.Name, ":") && !hasSuffix(vs.Name, counterSuffixes) && !underHistogramFunction(path) {
+			msgs = append(msgs, fmt.Sprintf("%s of %s, which is not named like a counter, counters end with %s, use deriv or delta for gauges", call.Func.Name, vs.Name, strings.Join(counterSuffixes, ", ")))
+		}
+	}
+	return msgs
+}
+
+// checkHistogramQuantile checks call, a call of histogram_quantile, for
+// classic histogram buckets aggregated without their le label.
+func checkHistogramQuantile(call *parser.Call) []string {
+	agg := outermostAggregation(call.Args[1])
+	if agg == nil || !selectsBuckets(agg.Expr) {
+		return nil
+	}
+	if agg.Without == slices.Contains(agg.Grouping, labels.BucketLabel) {
+		return []string{fmt.Sprintf("histogram_quantile over buckets aggregated without their %s label, keep it, e.g. %s by (%s, ...)", labels.BucketLabel, agg.Op, labels.BucketLabel)}
+	}
+	return nil
+}
+
+// underHistogramFunction returns whether path goes through a function
+// taking histograms, such as histogram_quantile.
+func underHistogramFunction(path []parser.Node) bool {
+	for _, node := range path {
+		if call, ok := node.(*parser.Call); ok && strings.HasPrefix(call.Func.Name, "histogram_") {
+			return true
+		}
+	}
+	return false
+}
+
+// selectsBuckets returns whether expr selects classic histogram buckets.
+func selectsBuckets(expr parser.Expr) bool {
+	found := false
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		if vs, ok := node.(*parser.VectorSelector); ok && strings.HasSuffix(vs.Name, "_bucket") {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// checkAbsent checks call, a call of absent or absent_over_time, for
+// selectors matching several series.
+func checkAbsent(call *parser.Call) []string {
+	var vs *parser.VectorSelector
+	switch arg := call.Args[0].(type) {
+	case *parser.VectorSelector:
+		vs = arg
+	case *parser.MatrixSelector:
+		var ok bool
+		if vs, ok = arg.VectorSelector.(*parser.VectorSelector); !ok {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	equality := false
+	for _, m := range vs.LabelMatchers {
+		switch {
+		case m.Name == labels.MetricName:
+		case m.Type == labels.MatchEqual:
+			equality = true
+		default:
+			return []string{fmt.Sprintf("%s(%s) only returns a result when every series matching %s is missing, not when one of them is, use equality matchers or compare a count", call.Func.Name, call.Args[0], m)}
+		}
+	}
+	if !equality {
+		return []string{fmt.Sprintf("%s(%s) only returns a result when every series of %s is missing, not when one of them is, select a single series with equality matchers or compare a count", call.Func.Name, call.Args[0], vs.Name)}
+	}
+	return nil
+}
+
+// isUnanchoredRegexp returns whether the regular expression re starts and
+// ends with .* around a pattern.
+func isUnanchoredRegexp(re string) bool {
+	inner, ok := strings.CutPrefix(re, ".*")
+	if !ok {
+		return false
+	}
+	inner, ok = strings.CutSuffix(inner, ".*")
+	return ok && inner != "" && !strings.ContainsAny(inner, "|()")
+}
+
+// checkComparison returns a message when the filtering comparison n can
+// never be true, or an empty string.
+func checkComparison(n *parser.BinaryExpr) string {
+	if !n.Op.IsComparisonOperator() || n.ReturnBool {
+		return ""
+	}
+
+	op, lhs, rhs := n.Op, n.LHS, n.RHS
+	if _, ok := unwrapParens(lhs).(*parser.NumberLiteral); ok {
+		// Compare the other side to the number.
+		op, lhs, rhs = flipComparison(op), rhs, lhs
+	}
+	c, ok := numberValue(rhs)
+	if !ok {
+		return ""
+	}
+	lo, hi, ok := valueRange(lhs)
+	if !ok {
+		return ""
+	}
+
+	never := false
+	switch op {
+	case parser.GTR:
+		never = hi <= c
+	case parser.GTE:
+		never = hi < c
+	case parser.LSS:
+		never = lo >= c
+	case parser.LTE:
+		never = lo > c
+	case parser.EQLC:
+		never = c < lo || c > hi
+	case parser.NEQ:
+		never = lo == c && hi == c
+	}
+	if !never {
+		return ""
+	}
+	if lo == hi {
+		return fmt.Sprintf("%s can never be true, %s is always %s", n, lhs, formatBound(lo))
+	}
+	return fmt.Sprintf("%s can never be true, %s is always between %s and %s", n, lhs, formatBound(lo), formatBound(hi))
+}
+
+// flipComparison returns the operator comparing the operands of op the
+// other way around.
+func flipComparison(op parser.ItemType) parser.ItemType {
+	switch op {
+	case parser.GTR:
+		return parser.LSS
+	case parser.GTE:
+		return parser.LTE
+	case parser.LSS:
+		return parser.GTR
+	case parser.LTE:
+		return parser.GTE
+	}
+	return op
+}
+
+// valueRange returns the bounds of the values of expr, ok is false when
+// they are unknown. Only numbers, absent, count, group, the functions
+// returning non-negative values and clamp, clamp_min and clamp_max with
+// number bounds are known, time() is not as the evaluation time is not.
+func valueRange(expr parser.Expr) (lo, hi float64, ok bool) {
+	switch e := unwrapParens(expr).(type) {
+	case *parser.NumberLiteral:
+		return e.Val, e.Val, true
+	case *parser.Call:
+		switch e.Func.Name {
+		case "absent", "absent_over_time":
+			return 1, 1, true
+		case "rate", "irate", "increase", "changes", "resets", "count_over_time", "abs", "sqrt":
+			return 0, math.Inf(1), true
+		case "clamp", "clamp_min", "clamp_max":
+			return clampRange(e)
+		}
+	case *parser.AggregateExpr:
+		switch e.Op {
+		case parser.GROUP:
+			return 1, 1, true
+		case parser.COUNT:
+			return 1, math.Inf(1), true
+		}
+	}
+	return 0, 0, false
+}
+
+// clampRange returns the bounds of the values of call, a call of clamp,
+// clamp_min or clamp_max, ok is false when its bounds are not numbers.
+func clampRange(call *parser.Call) (lo, hi float64, ok bool) {
+	lower, upper := math.Inf(-1), math.Inf(1)
+	bounds := call.Args[1:]
+	switch call.Func.Name {
+	case "clamp":
+		lower, ok = numberValue(bounds[0])
+		if !ok {
+			return 0, 0, false
+		}
+		upper, ok = numberValue(bounds[1])
+	case "clamp_min":
+		lower, ok = numberValue(bounds[0])
+	case "clamp_max":
+		upper, ok = numberValue(bounds[0])
+	}
+	// clamp returns no series at all when its min is greater than its max.
+	if !ok || lower > upper {
+		return 0, 0, false
+	}
+
+	lo, hi, ok = valueRange(call.Args[0])
+	if !ok {
+		return lower, upper, true
+	}
+	return math.Min(math.Max(lo, lower), upper), math.Min(math.Max(hi, lower), upper), true
+}
+
+// numberValue returns the value of expr, ok is false when it is not a
+// number.
+func numberValue(expr parser.Expr) (float64, bool) {
+	n, ok := unwrapParens(expr).(*parser.NumberLiteral)
+	if !ok {
+		return 0, false
+	}
+	return n.Val, true
+}
+
+// unwrapParens returns expr without the parentheses around it.
+func unwrapParens(expr parser.Expr) parser.Expr {
+	for {
+		p, ok := expr.(*parser.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = p.Expr
+	}
+}
+
+func formatBound(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return fmt.Sprint(f)
+}
+
+func hasSuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package promtool
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+func TestPromQLAntiPatterns(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{`sum by (job) (rate(http_requests_total[5m]))`, nil},
+		{`rate(job:http_requests:sum[5m])`, nil},
+		{`histogram_quantile(0.9, rate(http_request_duration_seconds[5m]))`, nil},
+		{`rate(sum(http_requests_total)[5m:])`, []string{
+			"rate over the result of sum loses the counter resets of the series it aggregates, apply rate first then aggregate, e.g. sum(rate(...))",
+		}},
+		{`rate(memory_usage_bytes[5m])`, []string{
+			"rate of memory_usage_bytes, which is not named like a counter, counters end with _total, _count, _sum, _bucket, use deriv or delta for gauges",
+		}},
+		{`histogram_quantile(0.9, sum by (le) (rate(http_request_duration_seconds_bucket[5m])))`, nil},
+		{`histogram_quantile(0.9, sum by (job) (rate(http_request_duration_seconds_bucket[5m])))`, []string{
+			"histogram_quantile over buckets aggregated without their le label, keep it, e.g. sum by (le, ...)",
+		}},
+		{`histogram_quantile(0.9, sum without (le) (rate(http_request_duration_seconds_bucket[5m])))`, []string{
+			"histogram_quantile over buckets aggregated without their le label, keep it, e.g. sum by (le, ...)",
+		}},
+		{`up{job=~".*api.*"}`, []string{
+			`job=~".*api.*" is anchored by Prometheus, the leading and trailing .* make it match any value containing api and slow it down, remove them if this is not the intent`,
+		}},
+		{`absent(up{job="api"})`, nil},
+		{`absent(up)`, []string{
+			"absent(up) only returns a result when every series of up is missing, not when one of them is, select a single series with equality matchers or compare a count",
+		}},
+		{`absent_over_time(up{job=~"api|web"}[5m])`, []string{
+			`absent_over_time(up{job=~"api|web"}[5m]) only returns a result when every series matching job=~"api|web" is missing, not when one of them is, use equality matchers or compare a count`,
+		}},
+		{`count(up) < 1`, []string{
+			"count(up) < 1 can never be true, count(up) is always between 1 and +Inf",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := promQLAntiPatterns(expr); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("promQLAntiPatterns() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckComparison(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`rate(http_requests_total[5m]) < 0`, "rate(http_requests_total[5m]) < 0 can never be true, rate(http_requests_total[5m]) is always between 0 and +Inf"},
+		{`rate(http_requests_total[5m]) <= 0`, ""},
+		{`0 > rate(http_requests_total[5m])`, "0 > rate(http_requests_total[5m]) can never be true, rate(http_requests_total[5m]) is always between 0 and +Inf"},
+		{`rate(http_requests_total[5m]) < bool 0`, ""},
+		{`abs(delta(temperature_celsius[5m])) == -1`, "abs(delta(temperature_celsius[5m])) == -1 can never be true, abs(delta(temperature_celsius[5m])) is always between 0 and +Inf"},
+		{`absent(up{job="api"}) != 1`, `absent(up{job="api"}) != 1 can never be true, absent(up{job="api"}) is always 1`},
+		{`absent(up{job="api"}) == 1`, ""},
+		{`group(up) > 1`, "group(up) > 1 can never be true, group(up) is always 1"},
+		{`count(up) >= 1`, ""},
+		{`(count(up)) < 1`, "(count(up)) < 1 can never be true, (count(up)) is always between 1 and +Inf"},
+		{`clamp(up, 0, 1) > 1`, "clamp(up, 0, 1) > 1 can never be true, clamp(up, 0, 1) is always between 0 and 1"},
+		{`clamp(up, 0, 1) >= 1`, ""},
+		{`clamp_min(up, 5) < 5`, "clamp_min(up, 5) < 5 can never be true, clamp_min(up, 5) is always between 5 and +Inf"},
+		{`clamp_max(up, 5) > 5`, "clamp_max(up, 5) > 5 can never be true, clamp_max(up, 5) is always between -Inf and 5"},
+		{`clamp_max(up, 5) < -10`, ""},
+		{`clamp_max(rate(http_requests_total[5m]), 5) < 0`, "clamp_max(rate(http_requests_total[5m]), 5) < 0 can never be true, clamp_max(rate(http_requests_total[5m]), 5) is always between 0 and 5"},
+		{`clamp(up, 1, 0) > 1`, ""},
+		{`clamp_min(up, scalar(up)) < 0`, ""},
+		{`up < 0`, ""},
+		{`up + 1`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n, ok := expr.(*parser.BinaryExpr)
+			if !ok {
+				t.Fatalf("%s is not a binary expression", tt.expr)
+			}
+			if got := checkComparison(n); got != tt.want {
+				t.Errorf("checkComparison() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsUnanchoredRegexp(t *testing.T) {
+	tests := []struct {
+		re   string
+		want bool
+	}{
+		{".*api.*", true},
+		{".*api-[0-9]+.*", true},
+		{"api", false},
+		{".*", false},
+		{".*.*", false},
+		{".*api", false},
+		{"api.*", false},
+		{".*(api|web).*", false},
+		{".*api|web.*", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.re, func(t *testing.T) {
+			if got := isUnanchoredRegexp(tt.re); got != tt.want {
+				t.Errorf("isUnanchoredRegexp(%q) = %v, want %v", tt.re, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package promtool
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// checkRecordingRuleNames returns the recording rules of files whose name
+// does not follow the level:metric:operations convention, whose level does
+// not match the labels kept by the outermost aggregation of their
+// expression, or that reuse the name of a raw metric.
+func checkRecordingRuleNames(files []ruleFile) []ruleFinding {
+	var findings []ruleFinding
+	for f, file := range files {
+		for i, group := range file.groups.Groups {
+			for j, rule := range group.Rules {
+				if rule.Record == "" {
+					continue
+				}
+				finding := func(format string, args ...any) {
+					findings = append(findings, ruleFinding{
+						fileIndex:  f,
+						groupIndex: i,
+						ruleIndex:  j,
+						message:    fmt.Sprintf("record %s ", rule.Record) + fmt.Sprintf(format, args...),
+					})
+				}
+
+				// Invalid expressions are already reported by rulefmt.
+				expr, err := parser.ParseExpr(rule.Expr)
+				if err != nil {
+					continue
+				}
+
+				if !strings.Contains(rule.Record, ":") {
+					finding("is named like a raw metric, recording rules have to be named level:metric:operations")
+					continue
+				}
+				if selectsMetric(expr, rule.Record) {
+					finding("has the name of a metric its expression selects")
+					continue
+				}
+				parts := strings.Split(rule.Record, ":")
+				// The level is empty when the expression aggregates every
+				// label away, e.g. :http_requests:rate5m.
+				if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+					finding("does not follow the level:metric:operations naming convention")
+					continue
+				}
+
+				agg := outermostAggregation(expr)
+				if agg == nil {
+					continue
+				}
+				level := parts[0]
+				switch {
+				case agg.Without:
+					for _, l := range agg.Grouping {
+						if levelHolds(level, l) {
+							finding("has level %s holding %s, which the outermost aggregation of its expression removes with without (%s)", level, l, strings.Join(agg.Grouping, ", "))
+							break
+						}
+					}
+				case levelMatches(level, agg.Grouping):
+				case len(agg.Grouping) == 0:
+					finding("has level %s but the outermost aggregation of its expression keeps no label, its level has to be empty", level)
+				case level == "":
+					finding("has no level but the outermost aggregation of its expression keeps by (%s)", strings.Join(agg.Grouping, ", "))
+				default:
+					finding("has level %s but the outermost aggregation of its expression keeps by (%s)", level, strings.Join(agg.Grouping, ", "))
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// selectsMetric returns whether expr selects the series of the metric name.
+func selectsMetric(expr parser.Expr, name string) bool {
+	found := false
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		vs, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		for _, m := range vs.LabelMatchers {
+			if m.Name == labels.MetricName && m.Type == labels.MatchEqual && m.Value == name {
+				found = true
+			}
+		}
+		return nil
+	})
+	return found
+}
+
+// outermostAggregation returns the first aggregation found walking the
+// syntax tree of expr from its root, or nil when there is none. The
+// aggregations keeping the labels of the series they select, such as topk,
+// are skipped.
+func outermostAggregation(node parser.Node) *parser.AggregateExpr {
+	if agg, ok := node.(*parser.AggregateExpr); ok {
+		switch agg.Op {
+		case parser.TOPK, parser.BOTTOMK, parser.LIMITK, parser.LIMIT_RATIO:
+		default:
+			return agg
+		}
+	}
+	for _, child := range parser.Children(node) {
+		if agg := outermostAggregation(child); agg != nil {
+			return agg
+		}
+	}
+	return nil
+}
+
+// levelHolds returns whether the underscore separated segments of label
+// appear in a row among the ones of level, e.g. instance in job_instance.
+func levelHolds(level, label string) bool {
+	segments, labelSegments := strings.Split(level, "_"), strings.Split(label, "_")
+	for i := 0; i+len(labelSegments) <= len(segments); i++ {
+		if slices.Equal(segments[i:i+len(labelSegments)], labelSegments) {
+			return true
+		}
+	}
+	return false
+}
+
+// levelMatches returns whether level is made of every label of grouping,
+// in any order, joined with underscores.
+func levelMatches(level string, grouping []string) bool {
+	if len(grouping) == 0 {
+		return level == ""
+	}
+	for i, l := range grouping {
+		rest, ok := strings.CutPrefix(level, l)
+		if !ok {
+			continue
+		}
+		others := append(append([]string{}, grouping[:i]...), grouping[i+1:]...)
+		if len(others) == 0 && rest == "" {
+			return true
+		}
+		if rest, ok := strings.CutPrefix(rest, "_"); ok && len(others) > 0 && levelMatches(rest, others) {
+			return true
+		}
+	}
+	return false
+}
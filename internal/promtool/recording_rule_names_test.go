@@ -0,0 +1,46 @@
+package promtool
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCheckRecordingRuleNames(t *testing.T) {
+	tests := []struct {
+		record string
+		expr   string
+		want   string
+	}{
+		{"job:http_requests:rate5m", "sum by (job) (rate(http_requests_total[5m]))", ""},
+		{"instance_job:http_requests:rate5m", "sum by (job, instance) (rate(http_requests_total[5m]))", ""},
+		{":http_requests:rate5m", "sum(rate(http_requests_total[5m]))", ""},
+		{"job:http_requests:rate5m", "sum without (instance) (rate(http_requests_total[5m]))", ""},
+		{"job_instance:http_requests:rate5m", "sum without (instance) (rate(http_requests_total[5m]))", "record job_instance:http_requests:rate5m has level job_instance holding instance, which the outermost aggregation of its expression removes with without (instance)"},
+		{"instance_job:http_requests:rate5m", "sum without (instance) (rate(http_requests_total[5m]))", "record instance_job:http_requests:rate5m has level instance_job holding instance, which the outermost aggregation of its expression removes with without (instance)"},
+		{"job_pod_name:http_requests:rate5m", "sum without (pod_name) (rate(http_requests_total[5m]))", "record job_pod_name:http_requests:rate5m has level job_pod_name holding pod_name, which the outermost aggregation of its expression removes with without (pod_name)"},
+		{"job_pod:http_requests:rate5m", "sum without (pod_name) (rate(http_requests_total[5m]))", ""},
+		{"jobs:http_requests:rate5m", "sum without (job) (rate(http_requests_total[5m]))", ""},
+		{"job:http_requests:rate5m", "sum by (instance) (rate(http_requests_total[5m]))", "record job:http_requests:rate5m has level job but the outermost aggregation of its expression keeps by (instance)"},
+		{"job:http_requests:rate5m", "sum(rate(http_requests_total[5m]))", "record job:http_requests:rate5m has level job but the outermost aggregation of its expression keeps no label, its level has to be empty"},
+		{"http_requests_total", "sum(http_requests_total)", "record http_requests_total is named like a raw metric, recording rules have to be named level:metric:operations"},
+		{"job:http_requests", "sum by (job) (http_requests_total)", "record job:http_requests does not follow the level:metric:operations naming convention"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.record, tt.expr), func(t *testing.T) {
+			rules := fmt.Sprintf("groups:\n- name: example\n  rules:\n  - record: %s\n    expr: %s\n", tt.record, tt.expr)
+			f, errs := parseRuleFile("", []byte(rules))
+			if len(errs) != 0 {
+				t.Fatal(errs)
+			}
+
+			got := ""
+			for _, finding := range checkRecordingRuleNames([]ruleFile{f}) {
+				got += finding.message
+			}
+			if got != tt.want {
+				t.Errorf("checkRecordingRuleNames() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
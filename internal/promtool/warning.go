@@ -0,0 +1,57 @@
+package promtool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// warningHandler is a slog.Handler turning the warnings logged by the
+// Prometheus packages into errors handed to warn, so that they are
+// reported to the caller instead of being written to the plugin output.
+type warningHandler struct {
+	warn  func(error)
+	attrs []slog.Attr
+}
+
+// newWarningLogger returns a logger calling warn for every record logged
+// at the warning level or above.
+func newWarningLogger(warn func(error)) *slog.Logger {
+	return slog.New(&warningHandler{warn: warn})
+}
+
+func (h *warningHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelWarn
+}
+
+func (h *warningHandler) Handle(_ context.Context, r slog.Record) error {
+	var attrs []string
+	appendAttr := func(a slog.Attr) bool {
+		attrs = append(attrs, fmt.Sprintf("%s=%v", a.Key, a.Value))
+		return true
+	}
+	for _, a := range h.attrs {
+		appendAttr(a)
+	}
+	r.Attrs(appendAttr)
+
+	msg := r.Message
+	if len(attrs) > 0 {
+		msg += " (" + strings.Join(attrs, ", ") + ")"
+	}
+	h.warn(errors.New(msg))
+	return nil
+}
+
+func (h *warningHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &warningHandler{
+		warn:  h.warn,
+		attrs: append(append([]slog.Attr{}, h.attrs...), attrs...),
+	}
+}
+
+func (h *warningHandler) WithGroup(_ string) slog.Handler {
+	return h
+}
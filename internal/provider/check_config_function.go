@@ -4,17 +4,79 @@ import (
 	"context"
 
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
 )
 
 // Ensure the implementation satisfies the desired interfaces.
 var _ function.Function = &CheckConfigFunction{}
 
+const configOptionsDescription = optionsDescription +
+	"\n- `files` (Map of String) Contents of the files referenced by the configuration keyed by path, used instead of the local filesystem." +
+	"\n- `check_rule_files` (Boolean) Whether to check the rule files referenced by the configuration like `check_rules` does, ignored with a warning along with `syntax_only` or `agent`. Defaults to `false`." +
+	"\n- `syntax_only` (Boolean) Whether to skip the checks of the files referenced by the configuration. Defaults to the `" + envSyntaxOnly + "` environment variable, or `false`." +
+	"\n- `base_dir` (String) Directory relative paths in the configuration are resolved against. Defaults to the `" + envBaseDir + "` environment variable." +
+	"\n- `fatal_warnings` (Boolean) Whether warnings, such as missing service discovery files, are errors. Defaults to the `" + envFatalWarnings + "` environment variable, or `false`." +
+	optionPrometheusVersionDescription +
+	optionFeatureFlagsDescription +
+	"\n- `agent` (Boolean) Whether to check the configuration for Prometheus in agent mode, like `--agent`. Defaults to `false`."
+
+// configOptions holds the options accepted by the functions checking a
+// Prometheus configuration.
+type configOptions struct {
+	Files             map[string]string `json:"files"`
+	CheckRuleFiles    bool              `json:"check_rule_files"`
+	SyntaxOnly        *bool             `json:"syntax_only"`
+	BaseDir           *string           `json:"base_dir"`
+	FatalWarnings     *bool             `json:"fatal_warnings"`
+	PrometheusVersion *string           `json:"prometheus_version"`
+	FeatureFlags      []string          `json:"feature_flags"`
+	Agent             bool              `json:"agent"`
+}
+
+func (o configOptions) promtoolOptions(settings providerSettings) promtool.ConfigOptions {
+	opts := promtool.ConfigOptions{
+		SyntaxOnly:        settings.SyntaxOnly,
+		CheckRuleFiles:    o.CheckRuleFiles,
+		BaseDir:           settings.BaseDir,
+		FatalWarnings:     settings.FatalWarnings,
+		Rules:             settings.rulesOptions(),
+		PrometheusVersion: settings.PrometheusVersion,
+		FeatureFlags:      settings.FeatureFlags,
+		Agent:             o.Agent,
+	}
+	if o.SyntaxOnly != nil {
+		opts.SyntaxOnly = *o.SyntaxOnly
+	}
+	if o.BaseDir != nil {
+		opts.BaseDir = *o.BaseDir
+	}
+	if o.FatalWarnings != nil {
+		opts.FatalWarnings = *o.FatalWarnings
+	}
+	if o.PrometheusVersion != nil {
+		opts.PrometheusVersion = *o.PrometheusVersion
+	}
+	if o.FeatureFlags != nil {
+		opts.FeatureFlags = o.FeatureFlags
+	}
+	if o.Files != nil {
+		opts.Files = promtool.MapFileSystem(o.Files)
+	}
+	return opts
+}
+
 type CheckConfigFunction struct {
+	provider *PromtoolProvider
 }
 
-func NewCheckConfigFunction() function.Function {
-	return &CheckConfigFunction{}
+func NewCheckConfigFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &CheckConfigFunction{
+			provider: p,
+		}
+	}
 }
 
 func (f *CheckConfigFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
@@ -31,17 +93,25 @@ func (f *CheckConfigFunction) Definition(ctx context.Context, req function.Defin
 				Description: "prometheus-config",
 			},
 		},
-		Return: function.BoolReturn{},
+		VariadicParameter: optionsParameter(configOptionsDescription),
+		Return:            function.BoolReturn{},
 	}
 }
 
 func (f *CheckConfigFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
 	var content string
-	if resp.Error = req.Arguments.Get(ctx, &content); resp.Error != nil {
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &content, &options); resp.Error != nil {
 		return
 	}
 
-	_, err := promtool.CheckConfig(content, false)
+	var opts configOptions
+	if resp.Error = decodeOptions(ctx, options, 1, &opts); resp.Error != nil {
+		return
+	}
+
+	_, warnings, err := promtool.CheckConfig(content, opts.promtoolOptions(f.provider.FunctionSettings()))
+	logConfigWarnings(ctx, warnings)
 
 	if err != nil {
 		resp.Error = function.ConcatFuncErrors(resp.Error, &function.FuncError{Text: err.Error()})
@@ -50,3 +120,15 @@ func (f *CheckConfigFunction) Run(ctx context.Context, req function.RunRequest,
 
 	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, true))
 }
+
+// logConfigWarnings logs the warnings raised while checking a configuration.
+// Provider functions cannot return warning diagnostics, so they are only
+// visible in the Terraform logs.
+func logConfigWarnings(ctx context.Context, warnings []promtool.ConfigDiagnostic) {
+	for _, w := range warnings {
+		tflog.Warn(ctx, w.Error(), map[string]interface{}{
+			"section": w.Section,
+			"name":    w.Name,
+		})
+	}
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"fmt"
+	"os"
 	"testing"
 )
 
@@ -14,6 +15,7 @@ func TestCheckConfig(t *testing.T) {
 		{
 			TestFile: "./testdata/config_invalid.yml",
 			Expected: false,
+			Error:    "yaml: unmarshal errors: line 20: field job_nzame not found in type config.ScrapeConfig",
 		},
 	}
 
@@ -22,6 +24,198 @@ func TestCheckConfig(t *testing.T) {
 	}
 }
 
+func TestCheckConfigFiles(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_valid_files.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/config_invalid_files.yml",
+			Expected: false,
+			Error:    `"/nonexistent/rules.yml" does not point to an existing file`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckConfig_files)
+	}
+}
+
+func TestCheckConfigSyntaxOnly(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_valid.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/config_invalid_files.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/config_invalid.yml",
+			Expected: false,
+			Error:    "yaml: unmarshal errors: line 20: field job_nzame not found in type config.ScrapeConfig",
+		},
+		{
+			TestFile: "./testdata/config_invalid_tls.yml",
+			Expected: false,
+			Error:    "exactly one of key or key_file must be configured when a client certificate is configured",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckConfig_syntaxOnly)
+	}
+}
+
+func TestCheckConfigBaseDir(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_relative_files.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/config_invalid_files.yml",
+			Expected: false,
+			Error:    `"/nonexistent/rules.yml" does not point to an existing file`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckConfig_baseDir)
+	}
+}
+
+func TestCheckConfigFatalWarnings(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_missing_sd_file.yml",
+			Options:  `{ fatal_warnings = false }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/config_missing_sd_file.yml",
+			Options:  `{ fatal_warnings = true }`,
+			Expected: false,
+			Error:    `file "/nonexistent/targets/*.json" for file_sd in scrape job "nodes" does not exist`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckConfig_options(tt.Options))
+	}
+}
+
+func TestCheckConfigPrometheusVersion(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_prometheus_2_53.yml",
+			Options:  `{ prometheus_version = "2.53.0" }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/config_prometheus_2_53.yml",
+			Options:  `{ prometheus_version = "2.45.0" }`,
+			Expected: false,
+			Error:    "configuration field global.rule_query_offset is not supported by Prometheus 2.45.0, it was introduced in 2.53.0",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckConfig_options(tt.Options))
+	}
+}
+
+func TestCheckConfigFeatureFlags(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_native_histograms.yml",
+			Options:  `{ fatal_warnings = true, feature_flags = [] }`,
+			Expected: false,
+			Error:    "scrape_configs[0].native_histogram_bucket_limit has no effect unless the native-histograms feature is enabled",
+		},
+		{
+			TestFile: "./testdata/config_native_histograms.yml",
+			Options:  `{ fatal_warnings = true, feature_flags = ["native-histograms"] }`,
+			Expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckConfig_options(tt.Options))
+	}
+}
+
+func TestCheckConfigAgent(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_valid.yml",
+			Options:  `{ agent = false, fatal_warnings = true }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/config_valid.yml",
+			Options:  `{ agent = true, fatal_warnings = true }`,
+			Expected: false,
+			Error:    "field alerting is not allowed in agent mode",
+		},
+		{
+			TestFile: "./testdata/config_agent.yml",
+			Options:  `{ agent = true, fatal_warnings = true }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/config_agent_tsdb.yml",
+			Options:  `{ agent = false, fatal_warnings = true }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/config_agent_tsdb.yml",
+			Options:  `{ agent = true, fatal_warnings = true }`,
+			Expected: false,
+			Error:    "storage.tsdb has no effect in agent mode",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckConfig_options(tt.Options))
+	}
+}
+
+func TestCheckConfigRuleFiles(t *testing.T) {
+	config, err := os.ReadFile("./testdata/config_valid_files.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_expr.yml",
+			Expected: false,
+			Error:    "/etc/prometheus/rules/alerts.yml:5:11: could not parse expression: 1:48: parse error: unexpected character inside braces: '>'",
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate.yml",
+			Expected: false,
+			Error:    `/etc/prometheus/rules/alerts.yml:11:5: duplicate rule HighRequestLatency{severity="page"}, might cause inconsistency while recording expressions`,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate_across.yml",
+			Expected: false,
+			Error:    "/etc/prometheus/rules/recording.yml:4:5: duplicate rule job:request_latency_seconds:mean5m{}, might cause inconsistency while recording expressions",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckConfig_ruleFiles(string(config)))
+	}
+}
+
 func testAccCheckConfig_basic(config string) string {
 	return fmt.Sprintf(`
 locals {
@@ -34,3 +228,107 @@ output "test" {
 }
 `, config)
 }
+
+func testAccCheckConfig_files(config string) string {
+	return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::check_config(local.config, {
+		files = {
+			"/etc/prometheus/rules/alerts.yml" = "groups: []"
+			"/etc/prometheus/token"            = "secret"
+			"/etc/prometheus/tls/cert.pem"     = "cert"
+			"/etc/prometheus/tls/key.pem"      = "key"
+			"/etc/prometheus/targets/a.json"   = jsonencode([{ targets = ["localhost:9100"] }])
+		}
+	})
+}
+`, config)
+}
+
+func testAccCheckConfig_syntaxOnly(config string) string {
+	return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::check_config(local.config, { syntax_only = true })
+}
+`, config)
+}
+
+func testAccCheckConfig_baseDir(config string) string {
+	return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::check_config(local.config, {
+		base_dir = "/etc/prometheus"
+		files = {
+			"/etc/prometheus/rules/alerts.yml"  = "groups: []"
+			"/etc/prometheus/tls/cert.pem"      = "cert"
+			"/etc/prometheus/tls/key.pem"       = "key"
+			"/etc/prometheus/targets/a.json"    = jsonencode([{ targets = ["localhost:9100"] }])
+		}
+	})
+}
+`, config)
+}
+
+func testAccCheckConfig_options(options string) PromtoolTerraformConfigBuilder {
+	return func(config string) string {
+		return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::check_config(local.config, %s)
+}
+`, config, options)
+	}
+}
+
+func testAccCheckConfig_ruleFiles(config string) PromtoolTerraformConfigBuilder {
+	return func(rules string) string {
+		return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+	rules = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::check_config(local.config, {
+		check_rule_files = true
+		files = {
+			"/etc/prometheus/rules/alerts.yml"    = local.rules
+			"/etc/prometheus/rules/recording.yml" = <<EOT
+groups:
+- name: recording
+  rules:
+  - record: job:request_latency_seconds:mean5m
+    expr: avg by (job) (rate(request_latency_seconds_sum[5m]) / rate(request_latency_seconds_count[5m]))
+EOT
+			"/etc/prometheus/token"               = "secret"
+			"/etc/prometheus/tls/cert.pem"        = "cert"
+			"/etc/prometheus/tls/key.pem"         = "key"
+			"/etc/prometheus/targets/a.json"      = jsonencode([{ targets = ["localhost:9100"] }])
+		}
+	})
+}
+`, config, rules)
+	}
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &CheckRuleFilesFunction{}
+
+type CheckRuleFilesFunction struct {
+	provider *PromtoolProvider
+}
+
+func NewCheckRuleFilesFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &CheckRuleFilesFunction{
+			provider: p,
+		}
+	}
+}
+
+func (f *CheckRuleFilesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "check_rule_files"
+}
+
+func (f *CheckRuleFilesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Validate several Prometheus rules configuration files together",
+		Description: "This function validates Prometheus rules configuration files like `check_rules` does, and lints them together to find the duplicate rules and group names spanning several files. The files are given either as a map of file names to their content, or as a list in which case they are named after their index, e.g. `files[0]`. Every problem found is reported along with the file it comes from.",
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:        "files",
+				Description: "A map of file names to prometheus-rules-config, or a list of prometheus-rules-config.",
+			},
+		},
+		VariadicParameter: optionsParameter(rulesOptionsDescription),
+		Return:            function.BoolReturn{},
+	}
+}
+
+func (f *CheckRuleFilesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var files types.Dynamic
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &files, &options); resp.Error != nil {
+		return
+	}
+
+	contents, funcErr := decodeRuleFiles(ctx, files, 0)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	var opts rulesOptions
+	if resp.Error = decodeOptions(ctx, options, 1, &opts); resp.Error != nil {
+		return
+	}
+
+	errs, warnings := promtool.ValidateRuleFiles(contents, opts.promtoolOptions(f.provider.FunctionSettings()))
+	for _, w := range warnings {
+		tflog.Warn(ctx, w.Message, map[string]interface{}{
+			"file":  w.File,
+			"group": w.Group,
+			"rule":  w.Rule,
+		})
+	}
+
+	if len(errs) != 0 {
+		for _, err := range errs {
+			text := err.Message
+			if pos := err.Position(); pos != "" {
+				text = fmt.Sprintf("%s: %s", pos, text)
+			}
+			resp.Error = function.ConcatFuncErrors(resp.Error, &function.FuncError{Text: text})
+		}
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, true))
+}
+
+// decodeRuleFiles returns the rules documents given as a map of file names
+// to their content, or as a list of contents named after their index.
+func decodeRuleFiles(ctx context.Context, files types.Dynamic, position int64) (map[string]string, *function.FuncError) {
+	if files.IsNull() || files.IsUnderlyingValueNull() {
+		return nil, function.NewArgumentFuncError(position, "files must not be null")
+	}
+
+	value, err := files.UnderlyingValue().ToTerraformValue(ctx)
+	if err != nil {
+		return nil, function.NewArgumentFuncError(position, err.Error())
+	}
+
+	raw, err := terraformValueToGo(value)
+	if err != nil {
+		return nil, function.NewArgumentFuncError(position, err.Error())
+	}
+
+	contents := map[string]string{}
+	switch {
+	case value.Type().Is(tftypes.Object{}), value.Type().Is(tftypes.Map{}):
+		for name, v := range raw.(map[string]any) {
+			content, ok := v.(string)
+			if !ok {
+				return nil, function.NewArgumentFuncError(position, fmt.Sprintf("files[%q] must be a string", name))
+			}
+			contents[name] = content
+		}
+	case value.Type().Is(tftypes.List{}), value.Type().Is(tftypes.Set{}), value.Type().Is(tftypes.Tuple{}):
+		for i, v := range raw.([]any) {
+			content, ok := v.(string)
+			if !ok {
+				return nil, function.NewArgumentFuncError(position, fmt.Sprintf("files[%d] must be a string", i))
+			}
+			contents[fmt.Sprintf("files[%d]", i)] = content
+		}
+	default:
+		return nil, function.NewArgumentFuncError(position, "files must be a map or a list of strings")
+	}
+	return contents, nil
+}
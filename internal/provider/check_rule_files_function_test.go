@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+const testAccRecordingRules = `groups:
+- name: recording
+  rules:
+  - record: job:request_latency_seconds:mean5m
+    expr: avg by (job) (rate(request_latency_seconds_sum[5m]) / rate(request_latency_seconds_count[5m]))
+`
+
+func TestCheckRuleFiles(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_expr.yml",
+			Expected: false,
+			Error: `team-a/alerts.yml:5:11: could not parse expression: 1:48: parse error: unexpected character inside braces: '>'
+team-a/alerts.yml:9:13: invalid field 'for' in recording rule
+team-b/recording.yml:4:5: duplicate rule job:request_latency_seconds:mean5m{}, might cause inconsistency while recording expressions`,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate_across.yml",
+			Expected: false,
+			Error: `team-b/recording.yml:4:5: duplicate rule job:request_latency_seconds:mean5m{}, might cause inconsistency while recording expressions
+team-b/recording.yml:2:3: group name "recording" is already used in team-a/alerts.yml`,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate_group_across.yml",
+			Expected: false,
+			Error:    `team-b/recording.yml:2:3: group name "recording" is already used in team-a/alerts.yml`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckRuleFiles_map)
+	}
+}
+
+func TestCheckRuleFilesList(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_expr.yml",
+			Expected: false,
+			Error: `files[0]:5:11: could not parse expression: 1:48: parse error: unexpected character inside braces: '>'
+files[0]:9:13: invalid field 'for' in recording rule
+files[1]:4:5: duplicate rule job:request_latency_seconds:mean5m{}, might cause inconsistency while recording expressions`,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate_across.yml",
+			Expected: false,
+			Error: `files[1]:4:5: duplicate rule job:request_latency_seconds:mean5m{}, might cause inconsistency while recording expressions
+files[1]:2:3: group name "recording" is already used in files[0]`,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate_group_across.yml",
+			Expected: false,
+			Error:    `files[1]:2:3: group name "recording" is already used in files[0]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckRuleFiles_list)
+	}
+}
+
+func TestCheckRuleFilesLint(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_invalid_duplicate_group_across.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate_across.yml",
+			Expected: false,
+			Error:    "files[1]:4:5: duplicate rule job:request_latency_seconds:mean5m{}, might cause inconsistency while recording expressions",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckRuleFiles_lintDuplicateRules)
+	}
+}
+
+func testAccCheckRuleFiles_map(rules string) string {
+	return fmt.Sprintf(`
+locals {
+	rules = <<EOT
+%s
+EOT
+	recording = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::check_rule_files({
+		"team-a/alerts.yml"    = local.rules
+		"team-b/recording.yml" = local.recording
+	})
+}
+`, rules, testAccRecordingRules)
+}
+
+func testAccCheckRuleFiles_list(rules string) string {
+	return fmt.Sprintf(`
+locals {
+	rules = <<EOT
+%s
+EOT
+	recording = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::check_rule_files([local.rules, local.recording])
+}
+`, rules, testAccRecordingRules)
+}
+
+func testAccCheckRuleFiles_lintDuplicateRules(rules string) string {
+	return fmt.Sprintf(`
+locals {
+	rules = <<EOT
+%s
+EOT
+	recording = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::check_rule_files([local.rules, local.recording], { lint = "duplicate-rules" })
+}
+`, rules, testAccRecordingRules)
+}
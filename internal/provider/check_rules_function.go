@@ -2,19 +2,164 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+	"gopkg.in/yaml.v3"
 )
 
 // Ensure the implementation satisfies the desired interfaces.
 var _ function.Function = &CheckRulesFunction{}
 
+const rulesOptionsDescription = optionsDescription +
+	"\n- `lint` (String) Comma separated list of lint categories to enable, like `--lint`, see the provider `lint` for the categories. Defaults to the `" + envLint + "` environment variable, or `all`." +
+	"\n- `lint_fatal` (Boolean) Whether lint findings are errors rather than warnings, like `--lint-fatal`. Defaults to the `" + envLintFatal + "` environment variable, or `true`." +
+	optionPrometheusVersionDescription +
+	optionFeatureFlagsDescription +
+	"\n- `alert_policy` (Object) Metadata the alerting rules have to carry, checked by the `alert-policy` lint category, see the provider `alert_policy` for its attributes. No policy is checked when it is not set."
+
+// rulesOptions holds the options accepted by the functions checking
+// Prometheus rules.
+type rulesOptions struct {
+	Lint              *string             `json:"lint"`
+	LintFatal         *bool               `json:"lint_fatal"`
+	PrometheusVersion *string             `json:"prometheus_version"`
+	FeatureFlags      []string            `json:"feature_flags"`
+	AlertPolicy       *alertPolicyOptions `json:"alert_policy"`
+}
+
+// alertPolicyOptions is the alert_policy option of the functions checking
+// Prometheus rules.
+type alertPolicyOptions struct {
+	RequiredLabels      map[string][]string `json:"required_labels"`
+	RequiredAnnotations []string            `json:"required_annotations"`
+	URLAnnotations      []string            `json:"url_annotations"`
+	CamelCaseNames      bool                `json:"camel_case_names"`
+	RequiredGroupLabels []string            `json:"required_group_labels"`
+}
+
+func (o alertPolicyOptions) promtoolPolicy() *promtool.AlertPolicy {
+	return &promtool.AlertPolicy{
+		RequiredLabels:      o.RequiredLabels,
+		RequiredAnnotations: o.RequiredAnnotations,
+		URLAnnotations:      o.URLAnnotations,
+		CamelCaseNames:      o.CamelCaseNames,
+		RequiredGroupLabels: o.RequiredGroupLabels,
+	}
+}
+
+func (o rulesOptions) promtoolOptions(settings providerSettings) promtool.RulesOptions {
+	opts := settings.rulesOptions()
+	if o.Lint != nil {
+		opts.Lint = *o.Lint
+	}
+	if o.LintFatal != nil {
+		opts.LintFatal = *o.LintFatal
+	}
+	if o.PrometheusVersion != nil {
+		opts.PrometheusVersion = *o.PrometheusVersion
+	}
+	if o.FeatureFlags != nil {
+		opts.FeatureFlags = o.FeatureFlags
+	}
+	if o.AlertPolicy != nil {
+		opts.AlertPolicy = o.AlertPolicy.promtoolPolicy()
+	}
+	return opts
+}
+
+// rulesParameterDescription describes the rules accepted by the functions
+// checking a single rules document.
+const rulesParameterDescription = "prometheus-rules-config, either as a YAML string or as an object with the same structure, e.g. `{ groups = [{ name = \"example\", rules = [...] }] }`."
+
+// decodeRulesDocument returns the rules document given either as a YAML
+// string or as an object, which is encoded to YAML. object tells whether an
+// object was given, its null attributes are left out.
+func decodeRulesDocument(ctx context.Context, rules types.Dynamic, position int64) (content string, object bool, funcErr *function.FuncError) {
+	if rules.IsNull() || rules.IsUnderlyingValueNull() {
+		return "", false, function.NewArgumentFuncError(position, "rules must not be null")
+	}
+
+	value, err := rules.UnderlyingValue().ToTerraformValue(ctx)
+	if err != nil {
+		return "", false, function.NewArgumentFuncError(position, err.Error())
+	}
+	raw, err := terraformValueToGo(value)
+	if err != nil {
+		return "", false, function.NewArgumentFuncError(position, err.Error())
+	}
+
+	switch {
+	case value.Type().Is(tftypes.String):
+		return raw.(string), false, nil
+	case value.Type().Is(tftypes.Object{}), value.Type().Is(tftypes.Map{}):
+		b, err := yaml.Marshal(yamlValue(raw))
+		if err != nil {
+			return "", true, function.NewArgumentFuncError(position, err.Error())
+		}
+		return string(b), true, nil
+	}
+	return "", false, function.NewArgumentFuncError(position, "rules must be a string or an object")
+}
+
+// yamlValue returns value, as returned by terraformValueToGo, with its
+// numbers converted so that they are not encoded as strings, and without
+// the null attributes of its objects.
+func yamlValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for k, e := range v {
+			if e != nil {
+				res[k] = yamlValue(e)
+			}
+		}
+		return res
+	case []any:
+		res := make([]any, 0, len(v))
+		for _, e := range v {
+			res = append(res, yamlValue(e))
+		}
+		return res
+	}
+	return value
+}
+
+// ruleDiagnosticText returns the text of the error reporting d. The path
+// of the faulty field is used for rules given as an object, since the
+// position in the YAML encoding is meaningless to the caller.
+func ruleDiagnosticText(d promtool.Diagnostic, object bool) string {
+	pos := d.Position()
+	if object {
+		pos = d.Path
+	}
+	if pos == "" {
+		return d.Message
+	}
+	return fmt.Sprintf("%s: %s", pos, d.Message)
+}
+
 type CheckRulesFunction struct {
+	provider *PromtoolProvider
 }
 
-func NewCheckRulesFunction() function.Function {
-	return &CheckRulesFunction{}
+func NewCheckRulesFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &CheckRulesFunction{
+			provider: p,
+		}
+	}
 }
 
 func (f *CheckRulesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
@@ -24,24 +169,45 @@ func (f *CheckRulesFunction) Metadata(ctx context.Context, req function.Metadata
 func (f *CheckRulesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
 	resp.Definition = function.Definition{
 		Summary:     "Validate Prometheus rules configuration",
-		Description: "This function validates a Prometheus rules configuration file.",
+		Description: "This function validates a Prometheus rules configuration file. The rules may also be given as an object, e.g. built in HCL, in which case the errors are reported with the path of the faulty attribute, such as `groups[2].rules[0].expr`.",
 		Parameters: []function.Parameter{
-			function.StringParameter{
+			function.DynamicParameter{
 				Name:        "config",
-				Description: "prometheus-config",
+				Description: rulesParameterDescription,
 			},
 		},
-		Return: function.BoolReturn{},
+		VariadicParameter: optionsParameter(rulesOptionsDescription),
+		Return:            function.BoolReturn{},
 	}
 }
 
 func (f *CheckRulesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
-	var content string
-	if resp.Error = req.Arguments.Get(ctx, &content); resp.Error != nil {
+	var rules types.Dynamic
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &rules, &options); resp.Error != nil {
+		return
+	}
+
+	content, object, funcErr := decodeRulesDocument(ctx, rules, 0)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	var opts rulesOptions
+	if resp.Error = decodeOptions(ctx, options, 1, &opts); resp.Error != nil {
+		return
+	}
+
+	if object {
+		f.runObject(ctx, content, opts, resp)
 		return
 	}
 
-	err := promtool.CheckRules(content, resp)
+	err, warnings := promtool.CheckRules(content, opts.promtoolOptions(f.provider.FunctionSettings()), resp)
+	for _, w := range warnings {
+		tflog.Warn(ctx, w.Error())
+	}
 	if err {
 		resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, false))
 		return
@@ -49,3 +215,20 @@ func (f *CheckRulesFunction) Run(ctx context.Context, req function.RunRequest, r
 
 	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, true))
 }
+
+// runObject checks the rules given as an object, reporting every problem
+// with the path of the faulty attribute.
+func (f *CheckRulesFunction) runObject(ctx context.Context, content string, opts rulesOptions, resp *function.RunResponse) {
+	errs, warnings := promtool.ValidateRules(content, opts.promtoolOptions(f.provider.FunctionSettings()))
+	for _, w := range warnings {
+		tflog.Warn(ctx, ruleDiagnosticText(w, true))
+	}
+	if len(errs) != 0 {
+		for _, err := range errs {
+			resp.Error = function.ConcatFuncErrors(resp.Error, &function.FuncError{Text: ruleDiagnosticText(err, true)})
+		}
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, true))
+}
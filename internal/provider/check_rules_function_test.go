@@ -2,7 +2,12 @@ package provider
 
 import (
 	"fmt"
+	"os"
+	"regexp"
 	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
 )
 
 func TestCheckRules(t *testing.T) {
@@ -14,6 +19,7 @@ func TestCheckRules(t *testing.T) {
 		{
 			TestFile: "./testdata/rules_invalid_duplicate.yml",
 			Expected: false,
+			Error:    "lint error 1 duplicate rule(s) found.\nMetric: HighRequestLatency\nLabel(s):\n\tseverity: page\nMight cause inconsistency while recording expressions",
 		},
 	}
 
@@ -22,6 +28,323 @@ func TestCheckRules(t *testing.T) {
 	}
 }
 
+func TestCheckRulesLint(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_invalid_duplicate.yml",
+			Options:  `{ lint = "none" }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate.yml",
+			Options:  `{ lint_fatal = false }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate.yml",
+			Options:  `{ lint = "duplicate-rules", lint_fatal = true }`,
+			Expected: false,
+			Error:    "lint error 1 duplicate rule(s) found.\nMetric: HighRequestLatency\nLabel(s):\n\tseverity: page\nMight cause inconsistency while recording expressions",
+		},
+		{
+			TestFile: "./testdata/rules_invalid_expr.yml",
+			Options:  `{ lint = "none" }`,
+			Expected: false,
+			Error:    `5:11: group "example", rule 1, "HighRequestLatency": could not parse expression: 1:48: parse error: unexpected character inside braces: '>'`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckRulesConfig_options(tt.Options))
+	}
+}
+
+func TestCheckRulesPrometheusVersion(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_prometheus_3.yml",
+			Options:  `{ prometheus_version = "3.0.0" }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_prometheus_3.yml",
+			Options:  `{ prometheus_version = "2.53.0" }`,
+			Expected: false,
+			Error:    "rule field groups[0].labels is not supported by Prometheus 2.53.0, it was introduced in 3.0.0",
+		},
+		{
+			TestFile: "./testdata/rules_prometheus_2.yml",
+			Options:  `{ prometheus_version = "2.53.0" }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_prometheus_2.yml",
+			Options:  `{ prometheus_version = "3.0.0" }`,
+			Expected: false,
+			Error:    `5:11: group "example", rule 1, "job:request_latency_seconds:smoothed": could not parse expression: 1:1: parse error: unknown function with name "holt_winters"`,
+		},
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Options:  `{ prometheus_version = "2.30" }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Options:  `{ prometheus_version = "latest" }`,
+			Expected: false,
+			Error:    `invalid Prometheus version "latest"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckRulesConfig_options(tt.Options))
+	}
+}
+
+func TestCheckRulesPrometheusVersionErrors(t *testing.T) {
+	testFile, err := os.ReadFile("./testdata/rules_prometheus_3.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every feature the version does not support is reported.
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccCheckRulesConfig_options(`{ prometheus_version = "2.40.0" }`)(string(testFile)),
+				ExpectError: regexp.MustCompile(`(?s)groups\[0\]\.labels.*keep_firing_for.*histogram_avg`),
+			},
+		},
+	})
+}
+
+func TestCheckRulesFeatureFlags(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_experimental_functions.yml",
+			Options:  `{}`,
+			Expected: false,
+			Error:    `5:11: group "example", rule 1, "job:request_latency_seconds:smoothed5m": could not parse expression: 1:1: parse error: function "double_exponential_smoothing" is not enabled`,
+		},
+		{
+			TestFile: "./testdata/rules_experimental_functions.yml",
+			Options:  `{ feature_flags = ["promql-experimental-functions"] }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_utf8_names.yml",
+			Options:  `{}`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_utf8_names.yml",
+			Options:  `{ prometheus_version = "2.53.0" }`,
+			Expected: false,
+			Error:    `4:13: group "example", rule 1, "job.request_latency_seconds.mean5m": invalid recording rule name: job.request_latency_seconds.mean5m`,
+		},
+		{
+			TestFile: "./testdata/rules_utf8_names.yml",
+			Options:  `{ prometheus_version = "2.53.0", feature_flags = ["utf8-names"] }`,
+			Expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckRulesConfig_options(tt.Options))
+	}
+}
+
+func TestCheckRulesAlertPolicy(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_alert_policy.yml",
+			Options:  `{}`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Options:  `{ alert_policy = { required_labels = { severity = [] }, required_annotations = ["summary"], camel_case_names = true } }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Options:  `{ alert_policy = { required_labels = { severity = ["critical", "warning"] } } }`,
+			Expected: false,
+			Error:    `lint error alert HighRequestLatency violates the required_labels policy: label "severity" is "page", allowed values are critical, warning`,
+		},
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Options:  `{ alert_policy = { required_group_labels = ["team"] } }`,
+			Expected: false,
+			Error:    `lint error group example violates the required_group_labels policy: label "team" is missing`,
+		},
+		{
+			TestFile: "./testdata/rules_alert_policy.yml",
+			Options:  `{ alert_policy = { required_labels = { team = [] }, required_group_labels = ["team"] } }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_alert_policy.yml",
+			Options:  `{ alert_policy = { camel_case_names = true } }`,
+			Expected: false,
+			Error:    "lint error alert high_error_rate violates the camel_case_names policy: the name is not in CamelCase",
+		},
+		{
+			TestFile: "./testdata/rules_alert_policy.yml",
+			Options:  `{ alert_policy = { required_annotations = ["summary"] } }`,
+			Expected: false,
+			Error:    `lint error alert high_error_rate violates the required_annotations policy: annotation "summary" is missing`,
+		},
+		{
+			TestFile: "./testdata/rules_alert_policy.yml",
+			Options:  `{ alert_policy = { url_annotations = ["runbook_url"] } }`,
+			Expected: false,
+			Error:    `lint error alert high_error_rate violates the url_annotations policy: annotation "runbook_url" is not an absolute URL: "runbooks/errors"`,
+		},
+		{
+			TestFile: "./testdata/rules_alert_policy.yml",
+			Options:  `{ lint = "duplicate-rules", alert_policy = { camel_case_names = true } }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_alert_policy.yml",
+			Options:  `{ lint_fatal = false, alert_policy = { camel_case_names = true } }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate_alert_policy.yml",
+			Options:  `{ lint_fatal = false, alert_policy = { camel_case_names = true } }`,
+			Expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckRulesConfig_options(tt.Options))
+	}
+}
+
+func TestCheckRulesDuplicateAndAlertPolicy(t *testing.T) {
+	testFile, err := os.ReadFile("./testdata/rules_invalid_duplicate_alert_policy.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The duplicate rules do not hide the other lint findings.
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccCheckRulesConfig_options(`{ alert_policy = { camel_case_names = true } }`)(string(testFile)),
+				ExpectError: regexp.MustCompile(`(?s)duplicate rule.*high_error_rate`),
+			},
+		},
+	})
+}
+
+func TestCheckRulesRecordingRuleNames(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_recording_rule_names.yml",
+			Options:  `{}`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_recording_rule_names.yml",
+			Options:  `{ lint = "recording-rule-names" }`,
+			Expected: false,
+			Error: `lint error record instance:http_requests:rate5m has level instance but the outermost aggregation of its expression keeps by (job)
+lint error record http_requests_rate5m is named like a raw metric, recording rules have to be named level:metric:operations
+lint error record cluster:http_requests:rate5m has level cluster but the outermost aggregation of its expression keeps no label, its level has to be empty`,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate_group_across.yml",
+			Options:  `{ lint = "all,recording-rule-names" }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_utf8_names.yml",
+			Options:  `{ lint = "recording-rule-names" }`,
+			Expected: false,
+			Error:    "lint error record job.request_latency_seconds.mean5m is named like a raw metric, recording rules have to be named level:metric:operations",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckRulesConfig_options(tt.Options))
+	}
+}
+
+func TestCheckRulesPromQLAntiPatterns(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_promql_anti_patterns.yml",
+			Options:  `{}`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_promql_anti_patterns.yml",
+			Options:  `{ lint = "promql-anti-patterns" }`,
+			Expected: false,
+			Error: `lint error record job:http_requests:rate5m: rate over the result of sum loses the counter resets of the series it aggregates, apply rate first then aggregate, e.g. sum(rate(...))
+lint error record instance:node_memory_free_bytes:rate5m: rate of node_memory_free_bytes, which is not named like a counter, counters end with _total, _count, _sum, _bucket, use deriv or delta for gauges
+lint error alert TargetMissing: absent(up) only returns a result when every series of up is missing, not when one of them is, select a single series with equality matchers or compare a count
+lint error alert NegativeRate: rate(http_requests_total{handler=~".*api.*"}[5m]) < 0 can never be true, rate(http_requests_total{handler=~".*api.*"}[5m]) is always between 0 and +Inf
+lint error alert NegativeRate: handler=~".*api.*" is anchored by Prometheus, the leading and trailing .* make it match any value containing api and slow it down, remove them if this is not the intent`,
+		},
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Options:  `{ lint = "all,promql-anti-patterns" }`,
+			Expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckRulesConfig_options(tt.Options))
+	}
+}
+
+func TestCheckRulesObject(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_object_valid.hcl",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_object_invalid_expr.hcl",
+			Expected: false,
+			Error:    "groups[1].rules[0].expr: could not parse expression: 1:50: parse error: unexpected end of input",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckRulesConfig_object)
+	}
+}
+
+func TestCheckRulesDecoded(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_prometheus_3.yml",
+			Expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccCheckRulesConfig_decoded)
+	}
+}
+
 func testAccCheckRulesConfig_basic(config string) string {
 	return fmt.Sprintf(`
 locals {
@@ -34,3 +357,42 @@ output "test" {
 }
 `, config)
 }
+
+func testAccCheckRulesConfig_options(options string) PromtoolTerraformConfigBuilder {
+	return func(config string) string {
+		return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::check_rules(local.config, %s)
+}
+`, config, options)
+	}
+}
+
+func testAccCheckRulesConfig_object(rules string) string {
+	return fmt.Sprintf(`
+locals {
+	rules = %s
+}
+output "test" {
+	value = provider::promtool::check_rules(local.rules)
+}
+`, rules)
+}
+
+func testAccCheckRulesConfig_decoded(config string) string {
+	return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::check_rules({ groups = provider::promtool::decode_rules(local.config) })
+}
+`, config)
+}
@@ -0,0 +1,738 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ datasource.DataSource = &ConfigDataSource{}
+
+type configDataSourceModel struct {
+	Global        *configGlobalModel        `tfsdk:"global"`
+	RuleFiles     types.List                `tfsdk:"rule_files"`
+	ScrapeConfigs []configScrapeConfigModel `tfsdk:"scrape_config"`
+	Alerting      *configAlertingModel      `tfsdk:"alerting"`
+	RemoteWrite   []configRemoteWriteModel  `tfsdk:"remote_write"`
+	YAML          types.String              `tfsdk:"yaml"`
+}
+
+type configGlobalModel struct {
+	ScrapeInterval     types.String `tfsdk:"scrape_interval"`
+	ScrapeTimeout      types.String `tfsdk:"scrape_timeout"`
+	ScrapeProtocols    types.List   `tfsdk:"scrape_protocols"`
+	EvaluationInterval types.String `tfsdk:"evaluation_interval"`
+	RuleQueryOffset    types.String `tfsdk:"rule_query_offset"`
+	ExternalLabels     types.Map    `tfsdk:"external_labels"`
+}
+
+type configScrapeConfigModel struct {
+	JobName                types.String               `tfsdk:"job_name"`
+	ScrapeInterval         types.String               `tfsdk:"scrape_interval"`
+	ScrapeTimeout          types.String               `tfsdk:"scrape_timeout"`
+	MetricsPath            types.String               `tfsdk:"metrics_path"`
+	Scheme                 types.String               `tfsdk:"scheme"`
+	HonorLabels            types.Bool                 `tfsdk:"honor_labels"`
+	HonorTimestamps        types.Bool                 `tfsdk:"honor_timestamps"`
+	SampleLimit            types.Int64                `tfsdk:"sample_limit"`
+	FallbackScrapeProtocol types.String               `tfsdk:"fallback_scrape_protocol"`
+	StaticConfigs          []configStaticConfigModel  `tfsdk:"static_config"`
+	FileSDConfigs          []configFileSDConfigModel  `tfsdk:"file_sd_config"`
+	RelabelConfigs         []configRelabelConfigModel `tfsdk:"relabel_config"`
+	MetricRelabelConfigs   []configRelabelConfigModel `tfsdk:"metric_relabel_config"`
+}
+
+type configStaticConfigModel struct {
+	Targets types.List `tfsdk:"targets"`
+	Labels  types.Map  `tfsdk:"labels"`
+}
+
+type configFileSDConfigModel struct {
+	Files           types.List   `tfsdk:"files"`
+	RefreshInterval types.String `tfsdk:"refresh_interval"`
+}
+
+type configRelabelConfigModel struct {
+	SourceLabels types.List   `tfsdk:"source_labels"`
+	Separator    types.String `tfsdk:"separator"`
+	Regex        types.String `tfsdk:"regex"`
+	Modulus      types.Int64  `tfsdk:"modulus"`
+	TargetLabel  types.String `tfsdk:"target_label"`
+	Replacement  types.String `tfsdk:"replacement"`
+	Action       types.String `tfsdk:"action"`
+}
+
+type configAlertingModel struct {
+	Alertmanagers       []configAlertmanagerModel  `tfsdk:"alertmanager"`
+	AlertRelabelConfigs []configRelabelConfigModel `tfsdk:"alert_relabel_config"`
+}
+
+type configAlertmanagerModel struct {
+	Scheme         types.String               `tfsdk:"scheme"`
+	PathPrefix     types.String               `tfsdk:"path_prefix"`
+	Timeout        types.String               `tfsdk:"timeout"`
+	APIVersion     types.String               `tfsdk:"api_version"`
+	StaticConfigs  []configStaticConfigModel  `tfsdk:"static_config"`
+	FileSDConfigs  []configFileSDConfigModel  `tfsdk:"file_sd_config"`
+	RelabelConfigs []configRelabelConfigModel `tfsdk:"relabel_config"`
+}
+
+type configRemoteWriteModel struct {
+	URL                 types.String               `tfsdk:"url"`
+	Name                types.String               `tfsdk:"name"`
+	RemoteTimeout       types.String               `tfsdk:"remote_timeout"`
+	Headers             types.Map                  `tfsdk:"headers"`
+	WriteRelabelConfigs []configRelabelConfigModel `tfsdk:"write_relabel_config"`
+}
+
+type ConfigDataSource struct {
+	provider *PromtoolProvider
+}
+
+func NewConfigDataSource(p *PromtoolProvider) func() datasource.DataSource {
+	return func() datasource.DataSource {
+		return &ConfigDataSource{
+			provider: p,
+		}
+	}
+}
+
+func (d *ConfigDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_config"
+}
+
+func (d *ConfigDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Builds a Prometheus configuration and renders it as a `prometheus.yml` file. " +
+			"The configuration is validated like `check_config` does, with the provider `syntax_only`, `base_dir`, `fatal_warnings`, `prometheus_version` and `feature_flags` settings, " +
+			"and the problems are reported on the block or attribute they come from when the data source is read.",
+		Attributes: map[string]schema.Attribute{
+			"rule_files": schema.ListAttribute{
+				Description: "The rule files to load, glob patterns are accepted.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"yaml": schema.StringAttribute{
+				Description: "The configuration file.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"global": schema.SingleNestedBlock{
+				Description: "The defaults of the other sections.",
+				Attributes: map[string]schema.Attribute{
+					"scrape_interval": schema.StringAttribute{
+						Description: "How often targets are scraped by default, e.g. `1m`.",
+						Optional:    true,
+					},
+					"scrape_timeout": schema.StringAttribute{
+						Description: "How long a scrape request times out after by default, e.g. `10s`.",
+						Optional:    true,
+					},
+					"scrape_protocols": schema.ListAttribute{
+						Description: "The protocols negotiated when scraping by default, in order of preference, e.g. `PrometheusText0.0.4`.",
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+					"evaluation_interval": schema.StringAttribute{
+						Description: "How often rules are evaluated by default, e.g. `1m`.",
+						Optional:    true,
+					},
+					"rule_query_offset": schema.StringAttribute{
+						Description: "The offset the queries of the rules are evaluated with by default, e.g. `1m`.",
+						Optional:    true,
+					},
+					"external_labels": schema.MapAttribute{
+						Description: "The labels added to the series and alerts sent to external systems.",
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+				},
+			},
+			"scrape_config": schema.ListNestedBlock{
+				Description: "A set of targets to scrape and how to scrape them.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"job_name": schema.StringAttribute{
+							Description: "The name of the job, it must be unique and is added as the `job` label of the scraped series.",
+							Required:    true,
+						},
+						"scrape_interval": schema.StringAttribute{
+							Description: "How often the targets are scraped, e.g. `1m`. Defaults to the global `scrape_interval`.",
+							Optional:    true,
+						},
+						"scrape_timeout": schema.StringAttribute{
+							Description: "How long a scrape request times out after, e.g. `10s`. Defaults to the global `scrape_timeout`.",
+							Optional:    true,
+						},
+						"metrics_path": schema.StringAttribute{
+							Description: "The HTTP path the metrics are fetched from. Defaults to `/metrics`.",
+							Optional:    true,
+						},
+						"scheme": schema.StringAttribute{
+							Description: "The protocol scheme of the scrape requests, `http` or `https`. Defaults to `http`.",
+							Optional:    true,
+						},
+						"honor_labels": schema.BoolAttribute{
+							Description: "Whether the labels of the scraped series win over the labels added by Prometheus when they conflict.",
+							Optional:    true,
+						},
+						"honor_timestamps": schema.BoolAttribute{
+							Description: "Whether the timestamps exposed by the targets are kept.",
+							Optional:    true,
+						},
+						"sample_limit": schema.Int64Attribute{
+							Description: "The maximum number of samples a scrape can return, `0` means no limit.",
+							Optional:    true,
+						},
+						"fallback_scrape_protocol": schema.StringAttribute{
+							Description: "The protocol used when a target returns a missing or invalid Content-Type, e.g. `PrometheusText0.0.4`.",
+							Optional:    true,
+						},
+					},
+					Blocks: map[string]schema.Block{
+						"static_config":         configStaticConfigBlock(),
+						"file_sd_config":        configFileSDConfigBlock(),
+						"relabel_config":        configRelabelConfigBlock("A relabeling of the targets before they are scraped."),
+						"metric_relabel_config": configRelabelConfigBlock("A relabeling of the scraped series before they are ingested."),
+					},
+				},
+			},
+			"alerting": schema.SingleNestedBlock{
+				Description: "Where the alerts are sent.",
+				Blocks: map[string]schema.Block{
+					"alertmanager": schema.ListNestedBlock{
+						Description: "A set of Alertmanagers the alerts are sent to.",
+						NestedObject: schema.NestedBlockObject{
+							Attributes: map[string]schema.Attribute{
+								"scheme": schema.StringAttribute{
+									Description: "The protocol scheme of the requests, `http` or `https`. Defaults to `http`.",
+									Optional:    true,
+								},
+								"path_prefix": schema.StringAttribute{
+									Description: "The prefix of the HTTP path the alerts are pushed to.",
+									Optional:    true,
+								},
+								"timeout": schema.StringAttribute{
+									Description: "How long a request pushing alerts times out after, e.g. `10s`.",
+									Optional:    true,
+								},
+								"api_version": schema.StringAttribute{
+									Description: "The version of the Alertmanager API, only `v2` is supported by Prometheus 3.",
+									Optional:    true,
+								},
+							},
+							Blocks: map[string]schema.Block{
+								"static_config":  configStaticConfigBlock(),
+								"file_sd_config": configFileSDConfigBlock(),
+								"relabel_config": configRelabelConfigBlock("A relabeling of the Alertmanagers discovered."),
+							},
+						},
+					},
+					"alert_relabel_config": configRelabelConfigBlock("A relabeling of the alerts before they are sent."),
+				},
+			},
+			"remote_write": schema.ListNestedBlock{
+				Description: "An endpoint the scraped series are sent to.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"url": schema.StringAttribute{
+							Description: "The URL of the endpoint.",
+							Required:    true,
+						},
+						"name": schema.StringAttribute{
+							Description: "The name of the endpoint, it must be unique.",
+							Optional:    true,
+						},
+						"remote_timeout": schema.StringAttribute{
+							Description: "How long a request times out after, e.g. `30s`.",
+							Optional:    true,
+						},
+						"headers": schema.MapAttribute{
+							Description: "The HTTP headers sent along with every request.",
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+					},
+					Blocks: map[string]schema.Block{
+						"write_relabel_config": configRelabelConfigBlock("A relabeling of the series before they are sent."),
+					},
+				},
+			},
+		},
+	}
+}
+
+func configStaticConfigBlock() schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		Description: "A static list of targets.",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"targets": schema.ListAttribute{
+					Description: "The addresses of the targets, as `host:port`.",
+					ElementType: types.StringType,
+					Required:    true,
+				},
+				"labels": schema.MapAttribute{
+					Description: "The labels added to the targets.",
+					ElementType: types.StringType,
+					Optional:    true,
+				},
+			},
+		},
+	}
+}
+
+func configFileSDConfigBlock() schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		Description: "Targets read from files.",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"files": schema.ListAttribute{
+					Description: "The files the targets are read from, glob patterns are accepted in the last path segment.",
+					ElementType: types.StringType,
+					Required:    true,
+				},
+				"refresh_interval": schema.StringAttribute{
+					Description: "How often the files are read again, e.g. `5m`.",
+					Optional:    true,
+				},
+			},
+		},
+	}
+}
+
+func configRelabelConfigBlock(description string) schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		Description: description,
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"source_labels": schema.ListAttribute{
+					Description: "The labels whose values are concatenated and matched against `regex`.",
+					ElementType: types.StringType,
+					Optional:    true,
+				},
+				"separator": schema.StringAttribute{
+					Description: "The separator of the concatenated values. Defaults to `;`.",
+					Optional:    true,
+				},
+				"regex": schema.StringAttribute{
+					Description: "The regular expression matched against the concatenated values. Defaults to `(.*)`.",
+					Optional:    true,
+				},
+				"modulus": schema.Int64Attribute{
+					Description: "The modulus of the hash of the concatenated values, for the `hashmod` action.",
+					Optional:    true,
+				},
+				"target_label": schema.StringAttribute{
+					Description: "The label the result is written to.",
+					Optional:    true,
+				},
+				"replacement": schema.StringAttribute{
+					Description: "The value written to `target_label`, `$1` and such refer to the groups of `regex`. Defaults to `$1`.",
+					Optional:    true,
+				},
+				"action": schema.StringAttribute{
+					Description: "The action to perform, e.g. `replace`, `keep`, `drop` or `labelmap`. Defaults to `replace`.",
+					Optional:    true,
+				},
+			},
+		},
+	}
+}
+
+func (d *ConfigDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data configDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	content, err := data.document()
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to encode the configuration", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(d.validate(data, content)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.YAML = types.StringValue(content)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// validate checks the configuration content like validate_config does and
+// reports the problems found on the blocks or attributes of m they come
+// from.
+func (d *ConfigDataSource) validate(m configDataSourceModel, content string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	errs, warnings := promtool.ValidateConfig(content, configOptions{}.promtoolOptions(d.provider.Settings()))
+	for _, e := range errs {
+		if p, ok := m.attributePath(e, content); ok {
+			diags.AddAttributeError(p, "Invalid configuration", e.Error())
+		} else {
+			diags.AddError("Invalid configuration", e.Error())
+		}
+	}
+	for _, w := range warnings {
+		if p, ok := m.attributePath(w, content); ok {
+			diags.AddAttributeWarning(p, "Configuration warning", w.Error())
+		} else {
+			diags.AddWarning("Configuration warning", w.Error())
+		}
+	}
+	return diags
+}
+
+// attributePath returns the path of the block or attribute of m the
+// problem d, found in content rendered from m, comes from. ok is false when
+// it cannot be attributed.
+func (m configDataSourceModel) attributePath(d promtool.ConfigDiagnostic, content string) (path.Path, bool) {
+	if p, ok := m.namedAttributePath(d); ok {
+		return p, true
+	}
+	if p, ok := configValuePath(content, d.Error()); ok {
+		return configAttributePath(p)
+	}
+	return path.Empty(), false
+}
+
+// namedAttributePath returns the path of the block or attribute of m named
+// by d. ok is false when d does not name any.
+func (m configDataSourceModel) namedAttributePath(d promtool.ConfigDiagnostic) (path.Path, bool) {
+	switch d.Section {
+	case promtool.ConfigSectionScrapeConfigs:
+		// The last job is the duplicate when the name is used twice.
+		index := -1
+		for i, sc := range m.ScrapeConfigs {
+			if sc.JobName.ValueString() == d.Name {
+				index = i
+			}
+		}
+		if index >= 0 {
+			return path.Root("scrape_config").AtListIndex(index).AtName("job_name"), true
+		}
+	case promtool.ConfigSectionRuleFiles:
+		for i, e := range m.RuleFiles.Elements() {
+			if s, ok := e.(types.String); ok && s.ValueString() == d.Name {
+				return path.Root("rule_files").AtListIndex(i), true
+			}
+		}
+	case promtool.ConfigSectionAlerting:
+		if strings.HasPrefix(d.Name, "alertmanagers[") {
+			return configAttributePath("alerting." + d.Name)
+		}
+	}
+	return configAttributePath(d.Name)
+}
+
+// configValuePath returns the path of the value of content, the
+// configuration rendered by the data source, quoted by message, e.g. the
+// target of `"localhost:9090/metrics" is not a valid hostname`. Many errors
+// of config.Load give neither a line nor a job name, but quote the faulty
+// value, and every value of content comes from an attribute. ok is false
+// when no value, or several, are quoted.
+func configValuePath(content, message string) (string, bool) {
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal([]byte(content), &doc); err != nil || len(doc.Content) == 0 {
+		return "", false
+	}
+
+	var found []string
+	var walk func(n *yaml3.Node, p string)
+	walk = func(n *yaml3.Node, p string) {
+		switch n.Kind {
+		case yaml3.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key := n.Content[i].Value
+				if p != "" {
+					key = p + "." + key
+				}
+				walk(n.Content[i+1], key)
+			}
+		case yaml3.SequenceNode:
+			for i, elem := range n.Content {
+				walk(elem, fmt.Sprintf("%s[%d]", p, i))
+			}
+		case yaml3.ScalarNode:
+			if n.Value != "" && strings.Contains(message, strconv.Quote(n.Value)) {
+				found = append(found, p)
+			}
+		}
+	}
+	walk(doc.Content[0], "")
+	if len(found) != 1 {
+		return "", false
+	}
+	return found[0], true
+}
+
+// configBlockKeys are the attributes naming the elements of the blocks,
+// Terraform cannot show the source of a whole block so the problems with
+// them are reported on those attributes.
+var configBlockKeys = map[string]string{
+	"scrape_configs": "job_name",
+	"remote_write":   "url",
+}
+
+// configBlockNames maps the fields of the rendered configuration to the
+// blocks they come from, the other fields are named like their attribute.
+var configBlockNames = map[string]string{
+	"scrape_configs":         "scrape_config",
+	"static_configs":         "static_config",
+	"file_sd_configs":        "file_sd_config",
+	"relabel_configs":        "relabel_config",
+	"metric_relabel_configs": "metric_relabel_config",
+	"alertmanagers":          "alertmanager",
+	"alert_relabel_configs":  "alert_relabel_config",
+	"write_relabel_configs":  "write_relabel_config",
+}
+
+// configMapAttributes are the map attributes, the rest of a path going
+// through them is the key of an element.
+var configMapAttributes = map[string]bool{
+	"external_labels": true,
+	"labels":          true,
+	"headers":         true,
+}
+
+// configAttributePath converts the path of a field in the configuration
+// rendered by the data source, e.g. scrape_configs[1].static_configs[0],
+// to the path of the block or attribute it comes from, e.g.
+// scrape_config[1].static_config[0]. A whole scrape config or remote write
+// is converted to the path of the attribute naming it. ok is false when the
+// field cannot be attributed.
+func configAttributePath(p string) (path.Path, bool) {
+	segments := strings.Split(p, ".")
+	switch m := ruleGroupPathSegment.FindStringSubmatch(segments[0]); {
+	case m == nil:
+		return path.Empty(), false
+	case m[1] != "global" && m[1] != "rule_files" && m[1] != "scrape_configs" && m[1] != "alerting" && m[1] != "remote_write":
+		return path.Empty(), false
+	}
+
+	if m := ruleGroupPathSegment.FindStringSubmatch(p); m != nil && m[2] != "" && configBlockKeys[m[1]] != "" {
+		segments = append(segments, configBlockKeys[m[1]])
+	}
+
+	var res path.Path
+	for i, segment := range segments {
+		m := ruleGroupPathSegment.FindStringSubmatch(segment)
+		if m == nil {
+			// Report the problem on the closest known parent.
+			return res, true
+		}
+		name := m[1]
+		if block, ok := configBlockNames[name]; ok {
+			name = block
+		}
+		if i == 0 {
+			res = path.Root(name)
+		} else {
+			res = res.AtName(name)
+		}
+		if m[2] != "" {
+			index, _ := strconv.Atoi(m[2])
+			res = res.AtListIndex(index)
+		}
+		if configMapAttributes[m[1]] && i+1 < len(segments) {
+			// The keys of the maps are not identifiers.
+			return res.AtMapKey(strings.Join(segments[i+1:], ".")), true
+		}
+	}
+	return res, true
+}
+
+// The append functions add the fields of the configuration rendered by the
+// data source to dst, leaving out the null values. The fields are added in
+// the order of the Prometheus documentation.
+
+func appendString(dst *yaml.MapSlice, key string, v types.String) {
+	if !v.IsNull() {
+		*dst = append(*dst, yaml.MapItem{Key: key, Value: v.ValueString()})
+	}
+}
+
+func appendBool(dst *yaml.MapSlice, key string, v types.Bool) {
+	if !v.IsNull() {
+		*dst = append(*dst, yaml.MapItem{Key: key, Value: v.ValueBool()})
+	}
+}
+
+func appendInt64(dst *yaml.MapSlice, key string, v types.Int64) {
+	if !v.IsNull() {
+		*dst = append(*dst, yaml.MapItem{Key: key, Value: v.ValueInt64()})
+	}
+}
+
+func appendList(dst *yaml.MapSlice, key string, v types.List) {
+	if v.IsNull() {
+		return
+	}
+	elems := []string{}
+	for _, e := range v.Elements() {
+		s, ok := e.(types.String)
+		if !ok {
+			continue
+		}
+		elems = append(elems, s.ValueString())
+	}
+	*dst = append(*dst, yaml.MapItem{Key: key, Value: elems})
+}
+
+func appendMap(dst *yaml.MapSlice, key string, v types.Map) {
+	elems := map[string]string{}
+	for k, e := range v.Elements() {
+		s, ok := e.(types.String)
+		if !ok {
+			continue
+		}
+		elems[k] = s.ValueString()
+	}
+	if len(elems) > 0 {
+		*dst = append(*dst, yaml.MapItem{Key: key, Value: elems})
+	}
+}
+
+func appendStaticConfigs(dst *yaml.MapSlice, configs []configStaticConfigModel) {
+	var items []yaml.MapSlice
+	for _, c := range configs {
+		var item yaml.MapSlice
+		appendList(&item, "targets", c.Targets)
+		appendMap(&item, "labels", c.Labels)
+		items = append(items, item)
+	}
+	if len(items) > 0 {
+		*dst = append(*dst, yaml.MapItem{Key: "static_configs", Value: items})
+	}
+}
+
+func appendFileSDConfigs(dst *yaml.MapSlice, configs []configFileSDConfigModel) {
+	var items []yaml.MapSlice
+	for _, c := range configs {
+		var item yaml.MapSlice
+		appendList(&item, "files", c.Files)
+		appendString(&item, "refresh_interval", c.RefreshInterval)
+		items = append(items, item)
+	}
+	if len(items) > 0 {
+		*dst = append(*dst, yaml.MapItem{Key: "file_sd_configs", Value: items})
+	}
+}
+
+func appendRelabelConfigs(dst *yaml.MapSlice, key string, configs []configRelabelConfigModel) {
+	var items []yaml.MapSlice
+	for _, c := range configs {
+		var item yaml.MapSlice
+		appendList(&item, "source_labels", c.SourceLabels)
+		appendString(&item, "separator", c.Separator)
+		appendString(&item, "regex", c.Regex)
+		appendInt64(&item, "modulus", c.Modulus)
+		appendString(&item, "target_label", c.TargetLabel)
+		appendString(&item, "replacement", c.Replacement)
+		appendString(&item, "action", c.Action)
+		items = append(items, item)
+	}
+	if len(items) > 0 {
+		*dst = append(*dst, yaml.MapItem{Key: key, Value: items})
+	}
+}
+
+// document returns the configuration file described by m.
+func (m configDataSourceModel) document() (string, error) {
+	var doc yaml.MapSlice
+	if g := m.Global; g != nil {
+		var global yaml.MapSlice
+		appendString(&global, "scrape_interval", g.ScrapeInterval)
+		appendString(&global, "scrape_timeout", g.ScrapeTimeout)
+		appendList(&global, "scrape_protocols", g.ScrapeProtocols)
+		appendString(&global, "evaluation_interval", g.EvaluationInterval)
+		appendString(&global, "rule_query_offset", g.RuleQueryOffset)
+		appendMap(&global, "external_labels", g.ExternalLabels)
+		if len(global) > 0 {
+			doc = append(doc, yaml.MapItem{Key: "global", Value: global})
+		}
+	}
+
+	appendList(&doc, "rule_files", m.RuleFiles)
+
+	var scrapeConfigs []yaml.MapSlice
+	for _, sc := range m.ScrapeConfigs {
+		var item yaml.MapSlice
+		appendString(&item, "job_name", sc.JobName)
+		appendString(&item, "scrape_interval", sc.ScrapeInterval)
+		appendString(&item, "scrape_timeout", sc.ScrapeTimeout)
+		appendString(&item, "metrics_path", sc.MetricsPath)
+		appendString(&item, "scheme", sc.Scheme)
+		appendBool(&item, "honor_labels", sc.HonorLabels)
+		appendBool(&item, "honor_timestamps", sc.HonorTimestamps)
+		appendInt64(&item, "sample_limit", sc.SampleLimit)
+		appendString(&item, "fallback_scrape_protocol", sc.FallbackScrapeProtocol)
+		appendStaticConfigs(&item, sc.StaticConfigs)
+		appendFileSDConfigs(&item, sc.FileSDConfigs)
+		appendRelabelConfigs(&item, "relabel_configs", sc.RelabelConfigs)
+		appendRelabelConfigs(&item, "metric_relabel_configs", sc.MetricRelabelConfigs)
+		scrapeConfigs = append(scrapeConfigs, item)
+	}
+	if len(scrapeConfigs) > 0 {
+		doc = append(doc, yaml.MapItem{Key: "scrape_configs", Value: scrapeConfigs})
+	}
+
+	if a := m.Alerting; a != nil {
+		var alerting yaml.MapSlice
+		var alertmanagers []yaml.MapSlice
+		for _, am := range a.Alertmanagers {
+			var item yaml.MapSlice
+			appendString(&item, "scheme", am.Scheme)
+			appendString(&item, "path_prefix", am.PathPrefix)
+			appendString(&item, "timeout", am.Timeout)
+			appendString(&item, "api_version", am.APIVersion)
+			appendStaticConfigs(&item, am.StaticConfigs)
+			appendFileSDConfigs(&item, am.FileSDConfigs)
+			appendRelabelConfigs(&item, "relabel_configs", am.RelabelConfigs)
+			alertmanagers = append(alertmanagers, item)
+		}
+		if len(alertmanagers) > 0 {
+			alerting = append(alerting, yaml.MapItem{Key: "alertmanagers", Value: alertmanagers})
+		}
+		appendRelabelConfigs(&alerting, "alert_relabel_configs", a.AlertRelabelConfigs)
+		if len(alerting) > 0 {
+			doc = append(doc, yaml.MapItem{Key: "alerting", Value: alerting})
+		}
+	}
+
+	var remoteWrite []yaml.MapSlice
+	for _, rw := range m.RemoteWrite {
+		var item yaml.MapSlice
+		appendString(&item, "url", rw.URL)
+		appendString(&item, "name", rw.Name)
+		appendString(&item, "remote_timeout", rw.RemoteTimeout)
+		appendMap(&item, "headers", rw.Headers)
+		appendRelabelConfigs(&item, "write_relabel_configs", rw.WriteRelabelConfigs)
+		remoteWrite = append(remoteWrite, item)
+	}
+	if len(remoteWrite) > 0 {
+		doc = append(doc, yaml.MapItem{Key: "remote_write", Value: remoteWrite})
+	}
+
+	if len(doc) == 0 {
+		// Render an empty file rather than {}.
+		return "", nil
+	}
+	b, err := yaml.Marshal(doc)
+	return string(b), err
+}
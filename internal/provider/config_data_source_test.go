@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestConfigDataSource(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfigDataSourceConfig_valid,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.promtool_config.test", "yaml", `global:
+  scrape_interval: 15s
+  evaluation_interval: 30s
+  external_labels:
+    region: eu-west-1
+scrape_configs:
+- job_name: prometheus
+  static_configs:
+  - targets:
+    - localhost:9090
+    labels:
+      env: prod
+  relabel_configs:
+  - source_labels:
+    - __address__
+    regex: (.*):9090
+    target_label: instance
+    replacement: $1
+alerting:
+  alertmanagers:
+  - static_configs:
+    - targets:
+      - alertmanager:9093
+remote_write:
+- url: https://remote.example.com/api/v1/write
+  name: remote
+`),
+				),
+			},
+		},
+	})
+}
+
+func TestConfigDataSourceInvalid(t *testing.T) {
+	tests := []struct {
+		Config string
+		Error  string
+	}{
+		{
+			Config: testAccConfigDataSourceConfig_scrapeConfig(`
+		scrape_interval = "10s"
+		scrape_timeout  = "20s"
+`),
+			Error: `(?s)job_name = "example".*scrape timeout greater than scrape interval`,
+		},
+		{
+			Config: testAccConfigDataSourceConfig_scrapeConfig(`
+		static_config {
+			targets = ["http://localhost:9090"]
+		}
+`),
+			Error: `(?s)targets = \["http://localhost:9090"\].*is not a valid hostname`,
+		},
+		{
+			Config: testAccConfigDataSourceConfig_scrapeConfig(`
+		relabel_config {
+			action = "rewrite"
+		}
+`),
+			Error: `(?s)action = "rewrite".*unknown relabel action "rewrite"`,
+		},
+		{
+			Config: `
+data "promtool_config" "test" {
+	scrape_config {
+		job_name = "example"
+	}
+
+	scrape_config {
+		job_name = "example" # duplicate
+	}
+}
+`,
+			Error: `(?s)job_name = "example" # duplicate.*found multiple scrape configs`,
+		},
+		{
+			Config: `
+data "promtool_config" "test" {
+	remote_write {
+		url = "://example.com"
+	}
+}
+`,
+			Error: `(?s)url = "://example.com".*missing protocol scheme`,
+		},
+	}
+
+	for _, tt := range tests {
+		resource.UnitTest(t, resource.TestCase{
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_8_0),
+			},
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config:      tt.Config,
+					ExpectError: regexp.MustCompile(tt.Error),
+				},
+			},
+		})
+	}
+}
+
+const testAccConfigDataSourceConfig_valid = `
+data "promtool_config" "test" {
+	global {
+		scrape_interval     = "15s"
+		evaluation_interval = "30s"
+		external_labels = {
+			region = "eu-west-1"
+		}
+	}
+
+	scrape_config {
+		job_name = "prometheus"
+
+		static_config {
+			targets = ["localhost:9090"]
+			labels = {
+				env = "prod"
+			}
+		}
+
+		relabel_config {
+			source_labels = ["__address__"]
+			regex         = "(.*):9090"
+			target_label  = "instance"
+			replacement   = "$1"
+		}
+	}
+
+	alerting {
+		alertmanager {
+			static_config {
+				targets = ["alertmanager:9093"]
+			}
+		}
+	}
+
+	remote_write {
+		url  = "https://remote.example.com/api/v1/write"
+		name = "remote"
+	}
+}
+`
+
+func testAccConfigDataSourceConfig_scrapeConfig(scrapeConfig string) string {
+	return `
+data "promtool_config" "test" {
+	scrape_config {
+		job_name = "example"
+` + scrapeConfig + `	}
+}
+`
+}
@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/rulefmt"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &DecodeRulesFunction{}
+
+var ruleAttrTypes = map[string]attr.Type{
+	"alert":           types.StringType,
+	"record":          types.StringType,
+	"expr":            types.StringType,
+	"for":             types.StringType,
+	"keep_firing_for": types.StringType,
+	"labels":          types.MapType{ElemType: types.StringType},
+	"annotations":     types.MapType{ElemType: types.StringType},
+}
+
+var ruleGroupAttrTypes = map[string]attr.Type{
+	"name":         types.StringType,
+	"interval":     types.StringType,
+	"limit":        types.Int64Type,
+	"query_offset": types.StringType,
+	"labels":       types.MapType{ElemType: types.StringType},
+	"rules":        types.ListType{ElemType: types.ObjectType{AttrTypes: ruleAttrTypes}},
+}
+
+type ruleModel struct {
+	Alert         types.String      `tfsdk:"alert"`
+	Record        types.String      `tfsdk:"record"`
+	Expr          string            `tfsdk:"expr"`
+	For           types.String      `tfsdk:"for"`
+	KeepFiringFor types.String      `tfsdk:"keep_firing_for"`
+	Labels        map[string]string `tfsdk:"labels"`
+	Annotations   map[string]string `tfsdk:"annotations"`
+}
+
+type ruleGroupModel struct {
+	Name        string            `tfsdk:"name"`
+	Interval    types.String      `tfsdk:"interval"`
+	Limit       int64             `tfsdk:"limit"`
+	QueryOffset types.String      `tfsdk:"query_offset"`
+	Labels      map[string]string `tfsdk:"labels"`
+	Rules       []ruleModel       `tfsdk:"rules"`
+}
+
+// newRuleGroupModels converts the rule groups parsed by rulefmt. Fields that
+// are not set are null, except labels and annotations that are empty maps so
+// that they can be merged without checking for null.
+func newRuleGroupModels(rgs *rulefmt.RuleGroups) []ruleGroupModel {
+	groups := make([]ruleGroupModel, 0, len(rgs.Groups))
+	for _, g := range rgs.Groups {
+		group := ruleGroupModel{
+			Name:        g.Name,
+			Interval:    durationValue(g.Interval),
+			Limit:       int64(g.Limit),
+			QueryOffset: types.StringNull(),
+			Labels:      stringMap(g.Labels),
+			Rules:       make([]ruleModel, 0, len(g.Rules)),
+		}
+		if g.QueryOffset != nil {
+			group.QueryOffset = types.StringValue(g.QueryOffset.String())
+		}
+		for _, r := range g.Rules {
+			group.Rules = append(group.Rules, ruleModel{
+				Alert:         stringValue(r.Alert),
+				Record:        stringValue(r.Record),
+				Expr:          r.Expr,
+				For:           durationValue(r.For),
+				KeepFiringFor: durationValue(r.KeepFiringFor),
+				Labels:        stringMap(r.Labels),
+				Annotations:   stringMap(r.Annotations),
+			})
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func stringValue(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
+
+func durationValue(d model.Duration) types.String {
+	if d == 0 {
+		return types.StringNull()
+	}
+	return types.StringValue(d.String())
+}
+
+func stringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+type DecodeRulesFunction struct {
+	provider *PromtoolProvider
+}
+
+func NewDecodeRulesFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &DecodeRulesFunction{
+			provider: p,
+		}
+	}
+}
+
+func (f *DecodeRulesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "decode_rules"
+}
+
+func (f *DecodeRulesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Decode Prometheus rules configuration",
+		Description: "This function parses and validates a Prometheus rules configuration file like Prometheus does, and returns its groups as a list of objects with the `name`, `interval`, `limit`, `query_offset`, `labels` and `rules` of each group. Each rule is an object with the `alert` or `record` name, the `expr`, the `for` and `keep_firing_for` durations, the `labels` and the `annotations` of the rule. Durations are strings such as `5m`, the fields that are not set are null, except `limit` that is `0` and the labels and annotations that are empty maps. The function fails when the rules are invalid.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "config",
+				Description: "prometheus-rules-config",
+			},
+		},
+		VariadicParameter: optionsParameter(promqlOptionsDescription),
+		Return: function.ListReturn{
+			ElementType: types.ObjectType{AttrTypes: ruleGroupAttrTypes},
+		},
+	}
+}
+
+func (f *DecodeRulesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content string
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &content, &options); resp.Error != nil {
+		return
+	}
+
+	var opts promqlOptions
+	if resp.Error = decodeOptions(ctx, options, 1, &opts); resp.Error != nil {
+		return
+	}
+
+	rgs, warnings, errs := promtool.ParseRules(content, opts.promtoolOptions(f.provider.FunctionSettings()))
+	for _, w := range warnings {
+		tflog.Warn(ctx, w.Error())
+	}
+	if len(errs) > 0 {
+		for _, err := range errs {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, err.Error()))
+		}
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, newRuleGroupModels(rgs)))
+}
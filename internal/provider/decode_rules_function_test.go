@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDecodeRules(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_prometheus_3.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_expr.yml",
+			Expected: false,
+			Error: `5:11: group "example", rule 1, "HighRequestLatency": could not parse expression: 1:48: parse error: unexpected character inside braces: '>'
+9:13: group "example", rule 2, "job:request_latency_seconds:mean5m": invalid field 'for' in recording rule`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccDecodeRules_basic)
+	}
+}
+
+func testAccDecodeRules_basic(config string) string {
+	return fmt.Sprintf(`
+locals {
+	groups = provider::promtool::decode_rules(%q)
+	rule   = local.groups[0].rules[0]
+}
+output "test" {
+	value = alltrue([
+		length(local.groups) == 1,
+		local.groups[0].name == "example",
+		local.groups[0].interval == null,
+		local.groups[0].limit == 0,
+		local.groups[0].labels == tomap({ team = "platform" }),
+		local.rule.alert == "HighRequestLatency",
+		local.rule.record == null,
+		local.rule.for == "10m",
+		local.rule.keep_firing_for == "5m",
+		local.rule.labels.severity == "page",
+		local.rule.annotations.summary == "High request latency",
+		startswith(local.rule.expr, "histogram_avg("),
+	])
+}
+`, config)
+}
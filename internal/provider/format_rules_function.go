@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &FormatRulesFunction{}
+
+type FormatRulesFunction struct {
+	provider *PromtoolProvider
+}
+
+func NewFormatRulesFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &FormatRulesFunction{
+			provider: p,
+		}
+	}
+}
+
+func (f *FormatRulesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "format_rules"
+}
+
+func (f *FormatRulesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Format Prometheus rules configuration",
+		Description: "This function parses a Prometheus rules configuration file and returns it in canonical form: the fields of groups and rules in the order Prometheus defines them, labels and annotations sorted by name, and every `expr` pretty-printed like `promql_format` does, as a block scalar when it spans several lines. Comments are kept on the fields they are attached to. Formatting the result again returns it unchanged. The function fails when the rules are invalid.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "config",
+				Description: "prometheus-rules-config",
+			},
+		},
+		VariadicParameter: optionsParameter(promqlFormatOptionsDescription),
+		Return:            function.StringReturn{},
+	}
+}
+
+func (f *FormatRulesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content string
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &content, &options); resp.Error != nil {
+		return
+	}
+
+	var opts promqlFormatOptions
+	if resp.Error = decodeOptions(ctx, options, 1, &opts); resp.Error != nil {
+		return
+	}
+
+	formatted, warnings, errs := promtool.FormatRules(content, opts.promtoolOptions(f.provider.FunctionSettings()))
+	for _, w := range warnings {
+		tflog.Warn(ctx, w.Error())
+	}
+	if len(errs) > 0 {
+		for _, err := range errs {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, err.Error()))
+		}
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, formatted))
+}
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormatRules(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_unformatted.yml",
+			Expected: true,
+			Output: `groups:
+  - name: errors
+    interval: 1m
+    rules:
+      - record: job:http_requests_errors:ratio_rate5m
+        expr: |2-
+            sum by (job) (rate(http_requests_total{code=~"5.."}[5m] offset 1h))
+          / ignoring (code) group_left ()
+            sum by (job) (rate(http_requests_total[5m]))
+        labels:
+          a: "1"
+          b: "2"
+`,
+		},
+		{
+			TestFile: "./testdata/rules_comments.yml",
+			Expected: true,
+			Output: `# Rules of the API.
+groups:
+  # Error ratios.
+  - name: errors # per job
+    rules:
+      # The error ratio.
+      - record: job:http_requests_errors:ratio_rate5m
+        expr: sum by (job) (rate(http_requests_errors_total[5m]))
+        labels:
+          a: "1"
+          b: "2" # second
+`,
+		},
+		{
+			TestFile: "./testdata/rules_invalid_expr.yml",
+			Expected: false,
+			Error: `5:11: group "example", rule 1, "HighRequestLatency": could not parse expression: 1:48: parse error: unexpected character inside braces: '>'
+9:13: group "example", rule 2, "job:request_latency_seconds:mean5m": invalid field 'for' in recording rule`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccFormatRules_basic(tt.Output))
+	}
+}
+
+func TestFormatRulesMaxWidth(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_unformatted.yml",
+			Options:  `{ max_width = 40 }`,
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_unformatted.yml",
+			Options:  `{ max_width = -1 }`,
+			Expected: false,
+			Error:    "invalid maximum width -1",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccFormatRules_options(tt.Options))
+	}
+}
+
+func TestFormatRulesIdempotent(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_unformatted.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_prometheus_3.yml",
+			Expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccFormatRules_idempotent)
+	}
+}
+
+func testAccFormatRules_basic(formatted string) PromtoolTerraformConfigBuilder {
+	return func(config string) string {
+		return fmt.Sprintf(`
+output "test" {
+	value = provider::promtool::format_rules(%q) == %q
+}
+`, config, formatted)
+	}
+}
+
+func testAccFormatRules_options(options string) PromtoolTerraformConfigBuilder {
+	return func(config string) string {
+		return fmt.Sprintf(`
+output "test" {
+	value = provider::promtool::format_rules(%q, %s) != ""
+}
+`, config, options)
+	}
+}
+
+func testAccFormatRules_idempotent(config string) string {
+	return fmt.Sprintf(`
+locals {
+	formatted = provider::promtool::format_rules(%q, { max_width = 40 })
+}
+output "test" {
+	value = provider::promtool::format_rules(local.formatted, { max_width = 40 }) == local.formatted
+}
+`, config)
+}
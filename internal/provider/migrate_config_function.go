@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &MigrateConfigFunction{}
+
+var migrationChangeAttrTypes = map[string]attr.Type{
+	"path":    types.StringType,
+	"message": types.StringType,
+}
+
+type migrationChangeModel struct {
+	Path    string `tfsdk:"path"`
+	Message string `tfsdk:"message"`
+}
+
+type migrateConfigResultModel struct {
+	Config   string                 `tfsdk:"config"`
+	Changes  []migrationChangeModel `tfsdk:"changes"`
+	Findings []migrationChangeModel `tfsdk:"findings"`
+}
+
+func newMigrationChangeModels(changes []promtool.MigrationChange) []migrationChangeModel {
+	models := make([]migrationChangeModel, 0, len(changes))
+	for _, c := range changes {
+		models = append(models, migrationChangeModel{
+			Path:    c.Path,
+			Message: c.Message,
+		})
+	}
+	return models
+}
+
+type MigrateConfigFunction struct {
+	provider *PromtoolProvider
+}
+
+func NewMigrateConfigFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &MigrateConfigFunction{
+			provider: p,
+		}
+	}
+}
+
+func (f *MigrateConfigFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "migrate_config"
+}
+
+func (f *MigrateConfigFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Migrate a Prometheus 2 configuration to Prometheus 3",
+		Description: "This function rewrites a Prometheus 2 configuration file for Prometheus 3. It returns an object with the migrated `config`, the list of `changes` made and the list of `findings` that have to be fixed by hand, such as targets that may need a `fallback_scrape_protocol`. Each change and finding has the YAML `path` of the field involved and a `message`. The migrated configuration is loaded like `check_config` does, the problems it still has are reported as findings. The configuration is returned unchanged, keeping its formatting, when there is nothing to rewrite.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "config",
+				Description: "prometheus-config",
+			},
+		},
+		VariadicParameter: optionsParameter(promqlOptionsDescription),
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"config":   types.StringType,
+				"changes":  types.ListType{ElemType: types.ObjectType{AttrTypes: migrationChangeAttrTypes}},
+				"findings": types.ListType{ElemType: types.ObjectType{AttrTypes: migrationChangeAttrTypes}},
+			},
+		},
+	}
+}
+
+func (f *MigrateConfigFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content string
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &content, &options); resp.Error != nil {
+		return
+	}
+
+	var opts promqlOptions
+	if resp.Error = decodeOptions(ctx, options, 1, &opts); resp.Error != nil {
+		return
+	}
+
+	res, warnings, err := promtool.MigrateConfig(content, opts.promtoolOptions(f.provider.FunctionSettings()))
+	for _, w := range warnings {
+		tflog.Warn(ctx, w.Error())
+	}
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, err.Error()))
+		return
+	}
+
+	result := migrateConfigResultModel{
+		Config:   res.Content,
+		Changes:  newMigrationChangeModels(res.Changes),
+		Findings: newMigrationChangeModels(res.Findings),
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_prometheus_2.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/config_valid.yml",
+			Expected: false,
+			NonFatal: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccMigrateConfig_changed)
+	}
+}
+
+func TestMigrateConfigValid(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_prometheus_2.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/config_valid.yml",
+			Expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccMigrateConfig_valid)
+	}
+}
+
+func TestMigrateConfigFindings(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_valid.yml",
+			Expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccMigrateConfig_findings)
+	}
+}
+
+func testAccMigrateConfig_changed(config string) string {
+	return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = length(provider::promtool::migrate_config(local.config).changes) > 0
+}
+`, config)
+}
+
+func testAccMigrateConfig_valid(config string) string {
+	return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::check_config(provider::promtool::migrate_config(local.config).config, { syntax_only = true })
+}
+`, config)
+}
+
+func testAccMigrateConfig_findings(config string) string {
+	return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = jsonencode(provider::promtool::migrate_config(local.config).findings) == jsonencode([{
+		message = "Prometheus 3 fails to scrape targets returning an invalid or missing Content-Type, set fallback_scrape_protocol on the jobs having such targets"
+		path    = "scrape_configs"
+	}])
+}
+`, config)
+}
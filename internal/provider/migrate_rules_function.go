@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &MigrateRulesFunction{}
+
+type migrateRulesResultModel struct {
+	Rules    string                 `tfsdk:"rules"`
+	Changes  []migrationChangeModel `tfsdk:"changes"`
+	Findings []migrationChangeModel `tfsdk:"findings"`
+}
+
+type MigrateRulesFunction struct {
+	provider *PromtoolProvider
+}
+
+func NewMigrateRulesFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &MigrateRulesFunction{
+			provider: p,
+		}
+	}
+}
+
+func (f *MigrateRulesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "migrate_rules"
+}
+
+func (f *MigrateRulesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Migrate Prometheus 2 rules to Prometheus 3",
+		Description: "This function rewrites a Prometheus 2 rules configuration file for Prometheus 3, e.g. renaming `holt_winters` to `double_exponential_smoothing`. It returns an object with the migrated `rules`, the list of `changes` made and the list of `findings` that have to be fixed by hand, such as regular expressions whose `.` now matches newlines or `le` matchers no longer matching normalised values. Each change and finding has the YAML `path` of the field involved and a `message`. The migrated rules are parsed like `check_rules` does, the problems they still have are reported as findings. The rules are returned unchanged, keeping their formatting, when there is nothing to rewrite.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "config",
+				Description: "prometheus-rules-config",
+			},
+		},
+		VariadicParameter: optionsParameter(promqlOptionsDescription),
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"rules":    types.StringType,
+				"changes":  types.ListType{ElemType: types.ObjectType{AttrTypes: migrationChangeAttrTypes}},
+				"findings": types.ListType{ElemType: types.ObjectType{AttrTypes: migrationChangeAttrTypes}},
+			},
+		},
+	}
+}
+
+func (f *MigrateRulesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content string
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &content, &options); resp.Error != nil {
+		return
+	}
+
+	var opts promqlOptions
+	if resp.Error = decodeOptions(ctx, options, 1, &opts); resp.Error != nil {
+		return
+	}
+
+	res, warnings, err := promtool.MigrateRules(content, opts.promtoolOptions(f.provider.FunctionSettings()))
+	for _, w := range warnings {
+		tflog.Warn(ctx, w.Error())
+	}
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, err.Error()))
+		return
+	}
+
+	result := migrateRulesResultModel{
+		Rules:    res.Content,
+		Changes:  newMigrationChangeModels(res.Changes),
+		Findings: newMigrationChangeModels(res.Findings),
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMigrateRules(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_prometheus_2.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Expected: false,
+			NonFatal: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccMigrateRules_renamed)
+	}
+}
+
+func testAccMigrateRules_renamed(config string) string {
+	return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+	migrated = provider::promtool::migrate_rules(local.config)
+}
+output "test" {
+	value = strcontains(local.migrated.rules, "double_exponential_smoothing(job:request_latency_seconds:mean5m[1h], 0.5, 0.5)") && length(local.migrated.changes) == 1
+}
+`, config)
+}
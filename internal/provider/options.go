@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// The descriptions of the options parameters list one option per line,
+// the options shared by several functions are described once here.
+const (
+	optionsDescription                 = "An optional object with the following attributes:\n"
+	optionFeatureFlagsDescription      = "\n- `feature_flags` (List of String) Prometheus feature flags, as given to `--enable-feature`, e.g. `promql-experimental-functions`. Defaults to the `" + envFeatureFlags + "` environment variable."
+	optionPrometheusVersionDescription = "\n- `prometheus_version` (String) Version of Prometheus to check against, reporting the fields and PromQL functions it does not support or deprecates. Defaults to the `" + envPrometheusVersion + "` environment variable."
+)
+
+// optionsParameter is the trailing variadic parameter used by functions
+// accepting options. A dynamic type is used so that callers only have to
+// set the options they care about.
+func optionsParameter(description string) function.Parameter {
+	return function.DynamicParameter{
+		Name:           "options",
+		Description:    description,
+		AllowNullValue: true,
+	}
+}
+
+// decodeOptions decodes the options passed to a function through
+// optionsParameter into target, a pointer to a struct with json tags.
+// Unknown options are rejected. position is the index of the options
+// parameter in the function definition.
+func decodeOptions(ctx context.Context, options []types.Dynamic, position int64, target any) *function.FuncError {
+	if len(options) == 0 {
+		return nil
+	}
+	if len(options) > 1 {
+		return function.NewArgumentFuncError(position, "at most one options argument can be given")
+	}
+	if options[0].IsNull() || options[0].IsUnderlyingValueNull() {
+		return nil
+	}
+
+	value, err := options[0].UnderlyingValue().ToTerraformValue(ctx)
+	if err != nil {
+		return function.NewArgumentFuncError(position, err.Error())
+	}
+	if !value.Type().Is(tftypes.Object{}) && !value.Type().Is(tftypes.Map{}) {
+		return function.NewArgumentFuncError(position, "options must be an object")
+	}
+
+	raw, err := terraformValueToGo(value)
+	if err != nil {
+		return function.NewArgumentFuncError(position, err.Error())
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return function.NewArgumentFuncError(position, err.Error())
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return function.NewArgumentFuncError(position, fmt.Sprintf("invalid options: %s", err))
+	}
+	return nil
+}
+
+// terraformValueToGo converts value to the Go types encoding/json produces
+// when decoding into an interface{}.
+func terraformValueToGo(value tftypes.Value) (any, error) {
+	if value.IsNull() {
+		return nil, nil
+	}
+	if !value.IsKnown() {
+		return nil, fmt.Errorf("options must be known")
+	}
+
+	typ := value.Type()
+	switch {
+	case typ.Is(tftypes.String):
+		var s string
+		err := value.As(&s)
+		return s, err
+	case typ.Is(tftypes.Bool):
+		var b bool
+		err := value.As(&b)
+		return b, err
+	case typ.Is(tftypes.Number):
+		n := new(big.Float)
+		if err := value.As(&n); err != nil {
+			return nil, err
+		}
+		return json.Number(n.Text('g', -1)), nil
+	case typ.Is(tftypes.Object{}), typ.Is(tftypes.Map{}):
+		var m map[string]tftypes.Value
+		if err := value.As(&m); err != nil {
+			return nil, err
+		}
+		res := make(map[string]any, len(m))
+		for k, v := range m {
+			gv, err := terraformValueToGo(v)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = gv
+		}
+		return res, nil
+	case typ.Is(tftypes.List{}), typ.Is(tftypes.Set{}), typ.Is(tftypes.Tuple{}):
+		var l []tftypes.Value
+		if err := value.As(&l); err != nil {
+			return nil, err
+		}
+		res := make([]any, 0, len(l))
+		for _, v := range l {
+			gv, err := terraformValueToGo(v)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, gv)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", typ)
+}
+
+// goToDynamic converts value, made of the Go types encoding/json produces
+// when decoding into an interface{}, to a Terraform value. Maps become
+// objects and slices become tuples, so that their elements may have
+// different types.
+func goToDynamic(value any) (attr.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return types.DynamicNull(), nil
+	case string:
+		return types.StringValue(v), nil
+	case bool:
+		return types.BoolValue(v), nil
+	case float64:
+		return types.NumberValue(big.NewFloat(v)), nil
+	case int:
+		return types.NumberValue(new(big.Float).SetInt64(int64(v))), nil
+	case map[string]any:
+		if v == nil {
+			return types.DynamicNull(), nil
+		}
+		attrTypes := make(map[string]attr.Type, len(v))
+		attrs := make(map[string]attr.Value, len(v))
+		for k, e := range v {
+			ev, err := goToDynamic(e)
+			if err != nil {
+				return nil, err
+			}
+			attrTypes[k] = ev.Type(context.Background())
+			attrs[k] = ev
+		}
+		obj, diags := types.ObjectValue(attrTypes, attrs)
+		if diags.HasError() {
+			return nil, fmt.Errorf("%s", diags.Errors()[0].Detail())
+		}
+		return obj, nil
+	case []any:
+		elemTypes := make([]attr.Type, 0, len(v))
+		elems := make([]attr.Value, 0, len(v))
+		for _, e := range v {
+			ev, err := goToDynamic(e)
+			if err != nil {
+				return nil, err
+			}
+			elemTypes = append(elemTypes, ev.Type(context.Background()))
+			elems = append(elems, ev)
+		}
+		tuple, diags := types.TupleValue(elemTypes, elems)
+		if diags.HasError() {
+			return nil, fmt.Errorf("%s", diags.Errors()[0].Detail())
+		}
+		return tuple, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", value)
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &PromQLFormatFunction{}
+
+const promqlFormatOptionsDescription = optionsDescription +
+	"\n- `max_width` (Number) Line width over which the expressions are split across several lines, like `promtool promql format`. Defaults to `100`." +
+	optionFeatureFlagsDescription
+
+// promqlFormatOptions holds the options accepted by the functions formatting
+// PromQL expressions.
+type promqlFormatOptions struct {
+	promqlOptions
+	MaxWidth int `json:"max_width"`
+}
+
+func (o promqlFormatOptions) promtoolOptions(settings providerSettings) promtool.FormatOptions {
+	return promtool.FormatOptions{
+		MaxWidth:     o.MaxWidth,
+		FeatureFlags: o.promqlOptions.promtoolOptions(settings).FeatureFlags,
+	}
+}
+
+type PromQLFormatFunction struct {
+	provider *PromtoolProvider
+}
+
+func NewPromQLFormatFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &PromQLFormatFunction{
+			provider: p,
+		}
+	}
+}
+
+func (f *PromQLFormatFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "promql_format"
+}
+
+func (f *PromQLFormatFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Format a PromQL expression",
+		Description: "This function returns the canonical pretty-printed form of a PromQL expression, like `promtool promql format` does. The parts of the expression longer than the maximum width are split across several lines and indented. The function fails when the expression is invalid.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "expr",
+				Description: "PromQL expression",
+			},
+		},
+		VariadicParameter: optionsParameter(promqlFormatOptionsDescription),
+		Return:            function.StringReturn{},
+	}
+}
+
+func (f *PromQLFormatFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var expr string
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &expr, &options); resp.Error != nil {
+		return
+	}
+
+	var opts promqlFormatOptions
+	if resp.Error = decodeOptions(ctx, options, 1, &opts); resp.Error != nil {
+		return
+	}
+
+	formatted, warnings, err := promtool.FormatPromQL(expr, opts.promtoolOptions(f.provider.FunctionSettings()))
+	for _, w := range warnings {
+		tflog.Warn(ctx, w.Error())
+	}
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, err.Error()))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, formatted))
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPromQLFormat(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/promql_valid.promql",
+			Options:  `{}`,
+			Expected: true,
+			Output: `  sum by (job) (rate(http_requests_total{code=~"5.."}[5m] offset 1h))
+/ ignoring (code) group_left ()
+  sum by (job) (rate(http_requests_total[5m]))`,
+		},
+		{
+			TestFile: "./testdata/promql_valid.promql",
+			Options:  `{ max_width = 40 }`,
+			Expected: true,
+			Output: `  sum by (job) (
+    rate(
+      http_requests_total{code=~"5.."}[5m] offset 1h
+    )
+  )
+/ ignoring (code) group_left ()
+  sum by (job) (
+    rate(http_requests_total[5m])
+  )`,
+		},
+		{
+			TestFile: "./testdata/promql_valid.promql",
+			Options:  `{ max_width = -1 }`,
+			Expected: false,
+			Error:    "invalid maximum width -1",
+		},
+		{
+			TestFile: "./testdata/promql_invalid.promql",
+			Options:  `{}`,
+			Expected: false,
+			Error:    "2:1: parse error: unclosed left parenthesis",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccPromQLFormat_options(tt.Options, tt.Output))
+	}
+}
+
+func testAccPromQLFormat_options(options, formatted string) PromtoolTerraformConfigBuilder {
+	return func(expr string) string {
+		return fmt.Sprintf(`
+locals {
+	formatted = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::promql_format(%q, %s) == chomp(local.formatted)
+}
+`, formatted, expr, options)
+	}
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &PromQLParseFunction{}
+
+const promqlOptionsDescription = optionsDescription + optionFeatureFlagsDescription
+
+// promqlOptions holds the options accepted by the functions parsing PromQL
+// expressions.
+type promqlOptions struct {
+	FeatureFlags []string `json:"feature_flags"`
+}
+
+func (o promqlOptions) promtoolOptions(settings providerSettings) promtool.PromQLOptions {
+	opts := promtool.PromQLOptions{
+		FeatureFlags: settings.FeatureFlags,
+	}
+	if o.FeatureFlags != nil {
+		opts.FeatureFlags = o.FeatureFlags
+	}
+	return opts
+}
+
+type PromQLParseFunction struct {
+	provider *PromtoolProvider
+}
+
+func NewPromQLParseFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &PromQLParseFunction{
+			provider: p,
+		}
+	}
+}
+
+func (f *PromQLParseFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "promql_parse"
+}
+
+func (f *PromQLParseFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Parse a PromQL expression",
+		Description: "This function parses a PromQL expression and returns its syntax tree. Every node is an object whose `type` attribute is one of " +
+			"`aggregation` (`op`, `expr`, `param`, `grouping`, `without`), " +
+			"`binary_expr` (`op`, `lhs`, `rhs`, `bool`, `matching` with `card`, `labels`, `on` and `include`), " +
+			"`call` (`function`, `args`, `return_type`), " +
+			"`vector_selector` (`name`, `matchers`, `offset`, `timestamp`, `start_or_end`), " +
+			"`matrix_selector` (the attributes of `vector_selector` and `range`), " +
+			"`subquery` (`expr`, `range`, `step`, `offset`, `timestamp`, `start_or_end`), " +
+			"`number_literal` (`value`), `string_literal` (`value`), `paren_expr` (`expr`), `unary_expr` (`op`, `expr`) or `duration_expr` (`value`). " +
+			"Matchers are objects with a `name`, a `type` among `=`, `!=`, `=~` and `!~`, and a `value`. " +
+			"Durations are strings formatted like in PromQL, e.g. `5m`, and the values of number literals are strings since they may be `NaN` or `Inf`. " +
+			"The function fails when the expression is invalid.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "expr",
+				Description: "PromQL expression",
+			},
+		},
+		VariadicParameter: optionsParameter(promqlOptionsDescription),
+		Return:            function.DynamicReturn{},
+	}
+}
+
+func (f *PromQLParseFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var expr string
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &expr, &options); resp.Error != nil {
+		return
+	}
+
+	var opts promqlOptions
+	if resp.Error = decodeOptions(ctx, options, 1, &opts); resp.Error != nil {
+		return
+	}
+
+	ast, warnings, err := promtool.ParsePromQL(expr, opts.promtoolOptions(f.provider.FunctionSettings()))
+	for _, w := range warnings {
+		tflog.Warn(ctx, w.Error())
+	}
+	if errors.Is(err, promtool.ErrUnsupportedNode) {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewFuncError(err.Error()))
+		return
+	}
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, err.Error()))
+		return
+	}
+
+	value, err := goToDynamic(ast)
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewFuncError(err.Error()))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, types.DynamicValue(value)))
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPromQLParse(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/promql_valid.promql",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/promql_invalid.promql",
+			Expected: false,
+			Error:    "2:1: parse error: unclosed left parenthesis",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccPromQLParse_basic)
+	}
+}
+
+func TestPromQLParseFeatureFlags(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/promql_experimental_functions.promql",
+			Options:  `{}`,
+			Expected: false,
+			Error:    `1:1: parse error: function "double_exponential_smoothing" is not enabled`,
+		},
+		{
+			TestFile: "./testdata/promql_experimental_functions.promql",
+			Options:  `{ feature_flags = ["promql-experimental-functions"] }`,
+			Expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccPromQLParse_options(tt.Options))
+	}
+}
+
+func testAccPromQLParse_basic(expr string) string {
+	return fmt.Sprintf(`
+locals {
+	ast = provider::promtool::promql_parse(%q)
+	errors = local.ast.lhs.expr.args[0]
+}
+output "test" {
+	value = alltrue([
+		local.ast.type == "binary_expr",
+		local.ast.op == "/",
+		local.ast.matching.card == "many-to-one",
+		local.ast.matching.on == false,
+		local.ast.matching.labels == ["code"],
+		local.ast.lhs.type == "aggregation",
+		local.ast.lhs.grouping == ["job"],
+		local.ast.lhs.param == null,
+		local.ast.lhs.expr.function == "rate",
+		local.errors.type == "matrix_selector",
+		local.errors.name == "http_requests_total",
+		local.errors.range == "5m",
+		local.errors.offset == "1h",
+		local.errors.timestamp == null,
+		anytrue([for m in local.errors.matchers : m.name == "code" && m.type == "=~" && m.value == "5.."]),
+	])
+}
+`, expr)
+}
+
+func testAccPromQLParse_options(options string) PromtoolTerraformConfigBuilder {
+	return func(expr string) string {
+		return fmt.Sprintf(`
+output "test" {
+	value = provider::promtool::promql_parse(%q, %s).function == "double_exponential_smoothing"
+}
+`, expr, options)
+	}
+}
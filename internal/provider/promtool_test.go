@@ -1,9 +1,11 @@
 package provider
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -12,7 +14,25 @@ import (
 
 type PromtoolTestCase struct {
 	TestFile string
+	// Options is the options object of the function, for the builders
+	// taking one.
+	Options string
+	// Output is the value the function returns, for the builders comparing
+	// it.
+	Output   string
 	Expected bool
+	// NonFatal is set for functions reporting invalid input through their
+	// result instead of failing.
+	NonFatal bool
+	// Error is the error the function fails with when the case is neither
+	// expected nor non fatal. Terraform wraps it, so it matches across line
+	// breaks.
+	Error string
+	// Errors and Warnings are every diagnostic listed, as formatted by the
+	// builder, by its errors and warnings outputs. They are only checked
+	// when set.
+	Errors   []string
+	Warnings []string
 }
 
 type PromtoolTerraformConfigBuilder func(string) string
@@ -23,17 +43,32 @@ func (i *PromtoolTestCase) Run(t *testing.T, makeTerraformConfig PromtoolTerrafo
 		t.Fatal(err)
 	}
 
+	checks := []resource.TestCheckFunc{
+		resource.TestCheckOutput("test", fmt.Sprintf("%t", i.Expected)),
+	}
+	for name, want := range map[string][]string{"errors": i.Errors, "warnings": i.Warnings} {
+		if want == nil {
+			continue
+		}
+		value, err := json.Marshal(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checks = append(checks, resource.TestCheckOutput(name, string(value)))
+	}
+
 	testStep := []resource.TestStep{
 		{
 			Config: makeTerraformConfig(string(testFile)),
-			Check: resource.ComposeAggregateTestCheckFunc(
-				resource.TestCheckOutput("test", fmt.Sprintf("%t", i.Expected)),
-			),
+			Check:  resource.ComposeAggregateTestCheckFunc(checks...),
 		},
 	}
 
-	if !i.Expected {
-		testStep[0].ExpectError = regexp.MustCompile(".*")
+	if !i.Expected && !i.NonFatal {
+		if i.Error == "" {
+			t.Fatalf("%s: the error the case fails with is not set", i.TestFile)
+		}
+		testStep[0].ExpectError = expectError(i.Error)
 	}
 
 	resource.UnitTest(t, resource.TestCase{
@@ -44,3 +79,13 @@ func (i *PromtoolTestCase) Run(t *testing.T, makeTerraformConfig PromtoolTerrafo
 		Steps:                    testStep,
 	})
 }
+
+// expectError returns a regular expression matching msg in the diagnostics
+// printed by Terraform, which wraps long lines.
+func expectError(msg string) *regexp.Regexp {
+	words := strings.Fields(msg)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return regexp.MustCompile(strings.Join(words, `\s+`))
+}
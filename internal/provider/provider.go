@@ -5,11 +5,17 @@ package provider
 
 import (
 	"context"
+	"sync"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
 )
 
 // Ensure PromtoolProvider satisfies various provider interfaces.
@@ -22,6 +28,33 @@ type PromtoolProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	mu       sync.RWMutex
+	settings providerSettings
+	// functionSettings are the defaults of the functions, read from the
+	// environment when the provider is created and never changed by
+	// Configure.
+	functionSettings providerSettings
+}
+
+// PromtoolProviderModel describes the provider data model.
+type PromtoolProviderModel struct {
+	BaseDir           types.String `tfsdk:"base_dir"`
+	SyntaxOnly        types.Bool   `tfsdk:"syntax_only"`
+	Lint              types.String `tfsdk:"lint"`
+	LintFatal         types.Bool   `tfsdk:"lint_fatal"`
+	FatalWarnings     types.Bool   `tfsdk:"fatal_warnings"`
+	FeatureFlags      types.List   `tfsdk:"feature_flags"`
+	PrometheusVersion types.String `tfsdk:"prometheus_version"`
+	AlertPolicy       types.Object `tfsdk:"alert_policy"`
+}
+
+type alertPolicyModel struct {
+	RequiredLabels      map[string][]string `tfsdk:"required_labels"`
+	RequiredAnnotations []string            `tfsdk:"required_annotations"`
+	URLAnnotations      []string            `tfsdk:"url_annotations"`
+	CamelCaseNames      types.Bool          `tfsdk:"camel_case_names"`
+	RequiredGroupLabels []string            `tfsdk:"required_group_labels"`
 }
 
 func (p *PromtoolProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -30,9 +63,169 @@ func (p *PromtoolProvider) Metadata(ctx context.Context, req provider.MetadataRe
 }
 
 func (p *PromtoolProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Terraform functions checking Prometheus configurations and rules, like `promtool` does. " +
+			"Terraform calls provider functions without configuring the provider first, so the functions only see the defaults set in the environment.",
+		Attributes: map[string]schema.Attribute{
+			"base_dir": schema.StringAttribute{
+				Description: "Default directory relative paths in the configurations of the `promtool_config` data source are resolved against. " +
+					"Can also be set with the `" + envBaseDir + "` environment variable, which is also the default of the functions.",
+				Optional: true,
+			},
+			"syntax_only": schema.BoolAttribute{
+				Description: "Whether the `promtool_config` data source only checks the syntax of its configuration, defaults to `false`. " +
+					"Can also be set with the `" + envSyntaxOnly + "` environment variable, which is also the default of the functions.",
+				Optional: true,
+			},
+			"lint": schema.StringAttribute{
+				Description: "Comma separated list of lint categories enabled by the data sources when checking rules, defaults to `all`. " +
+					"The categories are `all`, `duplicate-rules`, `duplicate-groups`, `alert-policy`, `recording-rule-names`, `promql-anti-patterns` and `none`. " +
+					"`recording-rule-names` checks that recording rules are named `level:metric:operations` with the level matching the labels kept by the outermost aggregation of their expression, or empty when it keeps none. " +
+					"`promql-anti-patterns` reports common mistakes in rule expressions, such as `rate` over aggregated results or gauges, `histogram_quantile` over buckets aggregated without `le`, unanchored regular expressions, comparisons that can never be true and `absent` over several series. " +
+					"Only the comparisons of numbers, `absent`, `count`, `group`, functions returning non-negative values such as `rate` or `abs`, and `clamp`, `clamp_min` or `clamp_max` with number bounds are checked, the values of other expressions are unknown. " +
+					"These two enforce conventions and are not enabled by `all`. " +
+					"Can also be set with the `" + envLint + "` environment variable, which is also the default of the functions.",
+				Optional: true,
+			},
+			"lint_fatal": schema.BoolAttribute{
+				Description: "Whether the data sources report lint findings as errors rather than warnings, defaults to `true`. " +
+					"Can also be set with the `" + envLintFatal + "` environment variable, which is also the default of the functions.",
+				Optional: true,
+			},
+			"fatal_warnings": schema.BoolAttribute{
+				Description: "Whether the `promtool_config` data source reports the warnings raised while checking its configuration as errors, defaults to `false`. " +
+					"Can also be set with the `" + envFatalWarnings + "` environment variable, which is also the default of the functions.",
+				Optional: true,
+			},
+			"feature_flags": schema.ListAttribute{
+				Description: "Prometheus feature flags, as given to `--enable-feature`, the data sources run their checks with, among `" + promtool.FeaturePromQLExperimentalFunctions + "`, `" + promtool.FeaturePromQLDurationExpr + "`, `" + promtool.FeatureNativeHistograms + "` and `" + promtool.FeatureUTF8Names + "`. " +
+					"Can also be set with the `" + envFeatureFlags + "` environment variable, as a comma separated list, which is also the default of the functions.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"prometheus_version": schema.StringAttribute{
+				Description: "Version of Prometheus the data sources check configurations and rules against, e.g. `2.53.0`. " +
+					"Fields and PromQL functions not supported by this version are reported as errors, deprecated ones as warnings. " +
+					"Every feature known to the provider is accepted when it is not set. " +
+					"Can also be set with the `" + envPrometheusVersion + "` environment variable, which is also the default of the functions.",
+				Optional: true,
+			},
+			"alert_policy": schema.SingleNestedAttribute{
+				Description: "Metadata the alerting rules of the `promtool_rule_group` data source have to carry, checked by the `alert-policy` lint category. " +
+					"The functions do not use it, they only check their own `alert_policy` option. " +
+					"Every violation is reported on the rule, or the group, along with the policy it violates.",
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"required_labels": schema.MapAttribute{
+						Description: "The labels every alert must have, mapped to their allowed values, any value is allowed when the list is empty. The labels of the group of the rule count, templated values are not checked.",
+						ElementType: types.ListType{ElemType: types.StringType},
+						Optional:    true,
+					},
+					"required_annotations": schema.ListAttribute{
+						Description: "The annotations every alert must have, e.g. `summary` and `description`.",
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+					"url_annotations": schema.ListAttribute{
+						Description: "The annotations that must be absolute URLs when they are set, e.g. `runbook_url`.",
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+					"camel_case_names": schema.BoolAttribute{
+						Description: "Whether alert names must be in CamelCase, e.g. `HighRequestLatency`, defaults to `false`.",
+						Optional:    true,
+					},
+					"required_group_labels": schema.ListAttribute{
+						Description: "The labels every group holding alerting rules must set.",
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+				},
+			},
+		},
+	}
 }
 
 func (p *PromtoolProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var data PromtoolProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.PrometheusVersion.IsNull() {
+		if _, err := promtool.ParseVersion(data.PrometheusVersion.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("prometheus_version"), "Invalid Prometheus version", err.Error())
+			return
+		}
+	}
+
+	var featureFlags []string
+	if !data.FeatureFlags.IsNull() {
+		resp.Diagnostics.Append(data.FeatureFlags.ElementsAs(ctx, &featureFlags, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var alertPolicy alertPolicyModel
+	if !data.AlertPolicy.IsNull() {
+		resp.Diagnostics.Append(data.AlertPolicy.As(ctx, &alertPolicy, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !data.BaseDir.IsNull() {
+		p.settings.BaseDir = data.BaseDir.ValueString()
+	}
+	if !data.SyntaxOnly.IsNull() {
+		p.settings.SyntaxOnly = data.SyntaxOnly.ValueBool()
+	}
+	if !data.Lint.IsNull() {
+		p.settings.Lint = data.Lint.ValueString()
+	}
+	if !data.LintFatal.IsNull() {
+		p.settings.LintFatal = data.LintFatal.ValueBool()
+	}
+	if !data.FatalWarnings.IsNull() {
+		p.settings.FatalWarnings = data.FatalWarnings.ValueBool()
+	}
+	if !data.FeatureFlags.IsNull() {
+		p.settings.FeatureFlags = featureFlags
+	}
+	if !data.PrometheusVersion.IsNull() {
+		p.settings.PrometheusVersion = data.PrometheusVersion.ValueString()
+	}
+	if !data.AlertPolicy.IsNull() {
+		p.settings.AlertPolicy = &promtool.AlertPolicy{
+			RequiredLabels:      alertPolicy.RequiredLabels,
+			RequiredAnnotations: alertPolicy.RequiredAnnotations,
+			URLAnnotations:      alertPolicy.URLAnnotations,
+			CamelCaseNames:      alertPolicy.CamelCaseNames.ValueBool(),
+			RequiredGroupLabels: alertPolicy.RequiredGroupLabels,
+		}
+	}
+}
+
+// Settings returns the defaults of the data sources, read from the
+// environment and overridden by the provider configuration.
+func (p *PromtoolProvider) Settings() providerSettings {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.settings
+}
+
+// FunctionSettings returns the defaults of the functions. Terraform calls
+// them on configured and unconfigured providers alike, so they only depend
+// on the environment to give the same results either way.
+func (p *PromtoolProvider) FunctionSettings() providerSettings {
+	return p.functionSettings
 }
 
 func (p *PromtoolProvider) Resources(ctx context.Context) []func() resource.Resource {
@@ -40,20 +233,35 @@ func (p *PromtoolProvider) Resources(ctx context.Context) []func() resource.Reso
 }
 
 func (p *PromtoolProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewRuleGroupDataSource(p),
+		NewConfigDataSource(p),
+	}
 }
 
 func (p *PromtoolProvider) Functions(ctx context.Context) []func() function.Function {
 	return []func() function.Function{
-		NewCheckRulesFunction,
-		NewCheckConfigFunction,
+		NewCheckRulesFunction(p),
+		NewCheckRuleFilesFunction(p),
+		NewCheckConfigFunction(p),
+		NewTestRulesFunction(p),
+		NewValidateRulesFunction(p),
+		NewValidateConfigFunction(p),
+		NewMigrateConfigFunction(p),
+		NewMigrateRulesFunction(p),
+		NewPromQLParseFunction(p),
+		NewPromQLFormatFunction(p),
+		NewFormatRulesFunction(p),
+		NewDecodeRulesFunction(p),
 	}
 }
 
 func New(version string) func() provider.Provider {
 	return func() provider.Provider {
 		return &PromtoolProvider{
-			version: version,
+			version:          version,
+			settings:         newProviderSettings(),
+			functionSettings: newProviderSettings(),
 		}
 	}
 }
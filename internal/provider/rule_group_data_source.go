@@ -0,0 +1,343 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+	"gopkg.in/yaml.v3"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ datasource.DataSource = &RuleGroupDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &RuleGroupDataSource{}
+
+type ruleGroupDataSourceModel struct {
+	Name        types.String              `tfsdk:"name"`
+	Interval    types.String              `tfsdk:"interval"`
+	QueryOffset types.String              `tfsdk:"query_offset"`
+	Limit       types.Int64               `tfsdk:"limit"`
+	Labels      types.Map                 `tfsdk:"labels"`
+	Rules       []ruleGroupDataSourceRule `tfsdk:"rule"`
+	YAML        types.String              `tfsdk:"yaml"`
+}
+
+type ruleGroupDataSourceRule struct {
+	Alert         types.String `tfsdk:"alert"`
+	Record        types.String `tfsdk:"record"`
+	Expr          types.String `tfsdk:"expr"`
+	For           types.String `tfsdk:"for"`
+	KeepFiringFor types.String `tfsdk:"keep_firing_for"`
+	Labels        types.Map    `tfsdk:"labels"`
+	Annotations   types.Map    `tfsdk:"annotations"`
+}
+
+type RuleGroupDataSource struct {
+	provider *PromtoolProvider
+}
+
+func NewRuleGroupDataSource(p *PromtoolProvider) func() datasource.DataSource {
+	return func() datasource.DataSource {
+		return &RuleGroupDataSource{
+			provider: p,
+		}
+	}
+}
+
+func (d *RuleGroupDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rule_group"
+}
+
+func (d *RuleGroupDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Builds a Prometheus rule group and renders it as a rules file. " +
+			"Every field is validated like `check_rules` does, with the provider `lint`, `lint_fatal`, `prometheus_version` and `feature_flags` settings, " +
+			"and the problems are reported on the attribute they come from, at plan time for the fields whose values are known.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "The name of the group, it must be unique within a rules file.",
+				Required:    true,
+			},
+			"interval": schema.StringAttribute{
+				Description: "How often the rules of the group are evaluated, e.g. `1m`. Defaults to the global `evaluation_interval`.",
+				Optional:    true,
+			},
+			"query_offset": schema.StringAttribute{
+				Description: "The offset the queries of the group are evaluated with, e.g. `1m`. Defaults to the global `rule_query_offset`.",
+				Optional:    true,
+			},
+			"limit": schema.Int64Attribute{
+				Description: "The maximum number of alerts an alerting rule and series a recording rule can produce, `0` means no limit.",
+				Optional:    true,
+			},
+			"labels": schema.MapAttribute{
+				Description: "Labels added to the series or alerts produced by the rules of the group.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"yaml": schema.StringAttribute{
+				Description: "The rules file holding the group, in canonical form like `format_rules` returns it, but with the expressions unchanged.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"rule": schema.ListNestedBlock{
+				Description: "A rule of the group, either an alerting rule when `alert` is set or a recording rule when `record` is set.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"alert": schema.StringAttribute{
+							Description: "The name of the alert.",
+							Optional:    true,
+						},
+						"record": schema.StringAttribute{
+							Description: "The name of the time series to output to.",
+							Optional:    true,
+						},
+						"expr": schema.StringAttribute{
+							Description: "The PromQL expression to evaluate.",
+							Required:    true,
+						},
+						"for": schema.StringAttribute{
+							Description: "How long an alert has to be active before firing, e.g. `5m`. Only for alerting rules.",
+							Optional:    true,
+						},
+						"keep_firing_for": schema.StringAttribute{
+							Description: "How long an alert keeps firing after its condition has cleared, e.g. `5m`. Only for alerting rules.",
+							Optional:    true,
+						},
+						"labels": schema.MapAttribute{
+							Description: "Labels to add or overwrite on the series or alerts produced by the rule.",
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+						"annotations": schema.MapAttribute{
+							Description: "Annotations to add to each alert. Only for alerting rules.",
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *RuleGroupDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data ruleGroupDataSourceModel
+
+	// The rules cannot be decoded while the rule blocks are unknown, they
+	// are validated when reading the data source.
+	if diags := req.Config.Get(ctx, &data); diags.HasError() {
+		return
+	}
+
+	content, unknown, err := data.document()
+	if err != nil {
+		return
+	}
+	// The warnings are only reported when reading, not to report them
+	// twice, and the errors that may come from unknown values once they
+	// are known.
+	resp.Diagnostics.Append(d.validate(content, unknown).Errors()...)
+}
+
+func (d *RuleGroupDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ruleGroupDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	content, _, err := data.document()
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to encode the rule group", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(d.validate(content, nil)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	settings := d.provider.Settings()
+	rgs, _, errs := promtool.ParseRules(content, promtool.PromQLOptions{FeatureFlags: settings.FeatureFlags})
+	for _, err := range errs {
+		resp.Diagnostics.AddError("Invalid rule group", err.Error())
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rendered, err := promtool.EncodeRules(rgs)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to render the rule group", err.Error())
+		return
+	}
+	data.YAML = types.StringValue(rendered)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// validate checks the rules document content like validate_rules does and
+// reports the problems found on the attributes they come from, leaving out
+// the ones affected by the unknown fields.
+func (d *RuleGroupDataSource) validate(content string, unknown []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	settings := d.provider.Settings()
+	opts := settings.rulesOptions()
+	opts.AlertPolicy = settings.AlertPolicy
+	errs, warnings := promtool.ValidateRules(content, opts)
+	for _, e := range errs {
+		if affectedByUnknown(e.Path, unknown) {
+			continue
+		}
+		if p, ok := ruleGroupAttributePath(e.Path); ok {
+			diags.AddAttributeError(p, "Invalid rule group", e.Message)
+		} else {
+			diags.AddError("Invalid rule group", e.Message)
+		}
+	}
+	for _, w := range warnings {
+		if affectedByUnknown(w.Path, unknown) {
+			continue
+		}
+		if p, ok := ruleGroupAttributePath(w.Path); ok {
+			diags.AddAttributeWarning(p, "Rule group warning", w.Message)
+		} else {
+			diags.AddWarning("Rule group warning", w.Message)
+		}
+	}
+	return diags
+}
+
+// document returns the rules file holding the group described by m. The
+// unknown values are left out, unknown holds the paths of the fields they
+// belong to in the rules file, e.g. groups[0].rules[1].expr.
+func (m ruleGroupDataSourceModel) document() (content string, unknown []string, err error) {
+	set := func(dst map[string]any, p, key string, v types.String) {
+		if v.IsUnknown() {
+			unknown = append(unknown, p+"."+key)
+		} else if !v.IsNull() {
+			dst[key] = v.ValueString()
+		}
+	}
+	setMap := func(dst map[string]any, p, key string, v types.Map) {
+		if v.IsUnknown() {
+			unknown = append(unknown, p+"."+key)
+			return
+		}
+		elems := map[string]string{}
+		for k, e := range v.Elements() {
+			if s, ok := e.(types.String); ok && !s.IsUnknown() {
+				elems[k] = s.ValueString()
+			} else {
+				unknown = append(unknown, p+"."+key)
+			}
+		}
+		if len(elems) > 0 {
+			dst[key] = elems
+		}
+	}
+
+	group := map[string]any{}
+	set(group, "groups[0]", "name", m.Name)
+	set(group, "groups[0]", "interval", m.Interval)
+	set(group, "groups[0]", "query_offset", m.QueryOffset)
+	if m.Limit.IsUnknown() {
+		unknown = append(unknown, "groups[0].limit")
+	} else if !m.Limit.IsNull() {
+		group["limit"] = m.Limit.ValueInt64()
+	}
+	setMap(group, "groups[0]", "labels", m.Labels)
+	if len(unknown) > 0 && unknown[len(unknown)-1] == "groups[0].labels" {
+		// The labels of the group count as labels of its rules, which
+		// cannot be checked either.
+		unknown = append(unknown, "groups[0]")
+	}
+
+	rules := make([]any, 0, len(m.Rules))
+	for i, r := range m.Rules {
+		p := fmt.Sprintf("groups[0].rules[%d]", i)
+		rule := map[string]any{}
+		set(rule, p, "alert", r.Alert)
+		set(rule, p, "record", r.Record)
+		set(rule, p, "expr", r.Expr)
+		set(rule, p, "for", r.For)
+		set(rule, p, "keep_firing_for", r.KeepFiringFor)
+		setMap(rule, p, "labels", r.Labels)
+		setMap(rule, p, "annotations", r.Annotations)
+		rules = append(rules, rule)
+	}
+	group["rules"] = rules
+
+	b, err := yaml.Marshal(map[string]any{"groups": []any{group}})
+	return string(b), unknown, err
+}
+
+// affectedByUnknown returns whether the problem found at the path p of the
+// rules file may come from one of the unknown fields. A problem is only
+// trusted when it is about a field that is neither one of them, nor holds
+// or is held by one of them.
+func affectedByUnknown(p string, unknown []string) bool {
+	if len(unknown) == 0 {
+		return false
+	}
+	if p == "" {
+		return true
+	}
+	within := func(p, parent string) bool {
+		return p == parent || strings.HasPrefix(p, parent+".") || strings.HasPrefix(p, parent+"[")
+	}
+	for _, u := range unknown {
+		if within(p, u) || within(u, p) {
+			return true
+		}
+	}
+	return false
+}
+
+var ruleGroupPathSegment = regexp.MustCompile(`^(\w+)(?:\[(\d+)\])?$`)
+
+// ruleGroupAttributePath converts the path of a field in the rules file
+// rendered by the data source, e.g. groups[0].rules[2].expr, to the path
+// of the attribute it comes from, e.g. rule[2].expr. ok is false when the
+// field is the group itself or cannot be attributed.
+func ruleGroupAttributePath(p string) (path.Path, bool) {
+	segments := strings.Split(p, ".")
+	if len(segments) < 2 || segments[0] != "groups[0]" {
+		return path.Empty(), false
+	}
+
+	var res path.Path
+	for i, segment := range segments[1:] {
+		m := ruleGroupPathSegment.FindStringSubmatch(segment)
+		switch {
+		case i == 0 && m != nil && m[1] == "rules":
+			res = path.Root("rule")
+			if m[2] == "" {
+				return res, true
+			}
+			index, _ := strconv.Atoi(m[2])
+			res = res.AtListIndex(index)
+		case i == 0:
+			res = path.Root(segment)
+		default:
+			res = res.AtName(segment)
+		}
+		if (segment == "labels" || segment == "annotations") && i+2 < len(segments) {
+			// The keys of the maps are not identifiers, they may hold
+			// dots.
+			return res.AtMapKey(strings.Join(segments[i+2:], ".")), true
+		}
+	}
+	return res, true
+}
@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestRuleGroupDataSource(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRuleGroupDataSourceConfig_valid,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.promtool_rule_group.test", "yaml", `groups:
+  - name: example
+    interval: 1m
+    rules:
+      - record: job:request_latency_seconds:mean5m
+        expr: avg by (job) (rate(request_latency_seconds_sum[5m]) / rate(request_latency_seconds_count[5m]))
+      - alert: HighRequestLatency
+        expr: job:request_latency_seconds:mean5m{job="myjob"} > 0.5
+        for: 10m
+        labels:
+          severity: page
+        annotations:
+          summary: High request latency
+    labels:
+      team: platform
+`),
+				),
+			},
+		},
+	})
+}
+
+func TestRuleGroupDataSourceInvalid(t *testing.T) {
+	tests := []struct {
+		Config string
+		Error  string
+	}{
+		{
+			Config: testAccRuleGroupDataSourceConfig_rule(`
+		record = "job:request_latency_seconds:mean5m"
+		expr   = "avg by (job) (rate(request_latency_seconds_sum[5m])"
+`),
+			Error: `(?s)expr   = "avg.*could not parse expression`,
+		},
+		{
+			Config: testAccRuleGroupDataSourceConfig_rule(`
+		record = "job:request_latency_seconds:mean5m"
+		expr   = "avg by (job) (rate(request_latency_seconds_sum[5m]))"
+		for    = "5m"
+`),
+			Error: `invalid field 'for' in recording rule`,
+		},
+		{
+			Config: testAccRuleGroupDataSourceConfig_rule(`
+		alert = "HighRequestLatency"
+		expr  = "job:request_latency_seconds:mean5m > 0.5"
+		for   = "ten minutes"
+`),
+			Error: `not a valid duration string`,
+		},
+		{
+			Config: testAccRuleGroupDataSourceConfig_rule(`
+		expr = "job:request_latency_seconds:mean5m > 0.5"
+`),
+			Error: `one of 'record' or 'alert' must be set`,
+		},
+	}
+
+	for _, tt := range tests {
+		resource.UnitTest(t, resource.TestCase{
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_8_0),
+			},
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config:      tt.Config,
+					ExpectError: regexp.MustCompile(tt.Error),
+				},
+			},
+		})
+	}
+}
+
+func TestRuleGroupDataSourceUnknownValues(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// The data source cannot be read before the severity is
+				// known, the known expression is checked at plan time.
+				Config: testAccRuleGroupDataSourceConfig_unknown(`
+		alert = "HighRequestLatency"
+		expr  = "job:request_latency_seconds:mean5m{job=\"myjob\" > 0.5"
+		labels = {
+			severity = terraform_data.severity.output
+		}
+`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`(?s)expr  = "job.*could not parse expression`),
+			},
+			{
+				// A missing expression may come from the unknown one.
+				Config: testAccRuleGroupDataSourceConfig_unknown(`
+		alert = "HighRequestLatency"
+		expr  = terraform_data.severity.output
+`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestRuleGroupAttributePath(t *testing.T) {
+	tests := []struct {
+		Path     string
+		Expected path.Path
+		OK       bool
+	}{
+		{
+			Path:     "groups[0].rules[2].expr",
+			Expected: path.Root("rule").AtListIndex(2).AtName("expr"),
+			OK:       true,
+		},
+		{
+			Path:     "groups[0].rules[0].annotations.runbook.example.com/url",
+			Expected: path.Root("rule").AtListIndex(0).AtName("annotations").AtMapKey("runbook.example.com/url"),
+			OK:       true,
+		},
+		{
+			Path:     "groups[0].labels.team.name",
+			Expected: path.Root("labels").AtMapKey("team.name"),
+			OK:       true,
+		},
+		{
+			Path:     "groups[0]",
+			Expected: path.Empty(),
+		},
+	}
+
+	for _, tt := range tests {
+		got, ok := ruleGroupAttributePath(tt.Path)
+		if ok != tt.OK || !got.Equal(tt.Expected) {
+			t.Errorf("ruleGroupAttributePath(%q) = %s, %t, expected %s, %t", tt.Path, got, ok, tt.Expected, tt.OK)
+		}
+	}
+}
+
+const testAccRuleGroupDataSourceConfig_valid = `
+data "promtool_rule_group" "test" {
+	name     = "example"
+	interval = "1m"
+	labels = {
+		team = "platform"
+	}
+
+	rule {
+		record = "job:request_latency_seconds:mean5m"
+		expr   = "avg by (job) (rate(request_latency_seconds_sum[5m]) / rate(request_latency_seconds_count[5m]))"
+	}
+
+	rule {
+		alert = "HighRequestLatency"
+		expr  = "job:request_latency_seconds:mean5m{job=\"myjob\"} > 0.5"
+		for   = "10m"
+		labels = {
+			severity = "page"
+		}
+		annotations = {
+			summary = "High request latency"
+		}
+	}
+}
+`
+
+func testAccRuleGroupDataSourceConfig_rule(rule string) string {
+	return `
+data "promtool_rule_group" "test" {
+	name = "example"
+
+	rule {` + rule + `	}
+}
+`
+}
+
+func testAccRuleGroupDataSourceConfig_unknown(rule string) string {
+	return `
+resource "terraform_data" "severity" {
+	input = "page"
+}
+
+data "promtool_rule_group" "test" {
+	name = "example"
+
+	rule {` + rule + `	}
+}
+`
+}
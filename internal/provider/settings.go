@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+)
+
+const (
+	envBaseDir           = "PROMTOOL_BASE_DIR"
+	envSyntaxOnly        = "PROMTOOL_SYNTAX_ONLY"
+	envLint              = "PROMTOOL_LINT"
+	envLintFatal         = "PROMTOOL_LINT_FATAL"
+	envFatalWarnings     = "PROMTOOL_FATAL_WARNINGS"
+	envFeatureFlags      = "PROMTOOL_FEATURE_FLAGS"
+	envPrometheusVersion = "PROMTOOL_PROMETHEUS_VERSION"
+)
+
+// providerSettings holds the defaults of the functions or of the data
+// sources of the provider. They are read from the environment, only the
+// ones of the data sources are then overridden by the provider
+// configuration.
+type providerSettings struct {
+	BaseDir           string
+	SyntaxOnly        bool
+	Lint              string
+	LintFatal         bool
+	FatalWarnings     bool
+	FeatureFlags      []string
+	PrometheusVersion string
+	// AlertPolicy is only set through the provider configuration, so it
+	// is nil in the defaults of the functions.
+	AlertPolicy *promtool.AlertPolicy
+}
+
+func newProviderSettings() providerSettings {
+	settings := providerSettings{
+		BaseDir:           os.Getenv(envBaseDir),
+		SyntaxOnly:        envBool(envSyntaxOnly, false),
+		Lint:              promtool.DefaultRulesOptions.Lint,
+		LintFatal:         envBool(envLintFatal, promtool.DefaultRulesOptions.LintFatal),
+		FatalWarnings:     envBool(envFatalWarnings, false),
+		PrometheusVersion: os.Getenv(envPrometheusVersion),
+	}
+	if v, ok := os.LookupEnv(envLint); ok {
+		settings.Lint = v
+	}
+	if v := os.Getenv(envFeatureFlags); v != "" {
+		for _, flag := range strings.Split(v, ",") {
+			if flag = strings.TrimSpace(flag); flag != "" {
+				settings.FeatureFlags = append(settings.FeatureFlags, flag)
+			}
+		}
+	}
+	return settings
+}
+
+// envBool returns the boolean value of the environment variable key, or
+// def when it is not set or not a boolean.
+func envBool(key string, def bool) bool {
+	b, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// rulesOptions returns the options used to check rules when a function is
+// not given any. The alert policy is left out, the functions only check
+// the one of their options.
+func (s providerSettings) rulesOptions() promtool.RulesOptions {
+	return promtool.RulesOptions{
+		Lint:              s.Lint,
+		LintFatal:         s.LintFatal,
+		PrometheusVersion: s.PrometheusVersion,
+		FeatureFlags:      s.FeatureFlags,
+	}
+}
@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+)
+
+func TestNewProviderSettings(t *testing.T) {
+	t.Setenv(envBaseDir, "/etc/prometheus")
+	t.Setenv(envSyntaxOnly, "true")
+	t.Setenv(envLint, "duplicate-rules")
+	t.Setenv(envLintFatal, "false")
+	t.Setenv(envFatalWarnings, "not-a-bool")
+	t.Setenv(envFeatureFlags, "promql-experimental-functions, ,utf8-names")
+	t.Setenv(envPrometheusVersion, "2.53.0")
+
+	expected := providerSettings{
+		BaseDir:           "/etc/prometheus",
+		SyntaxOnly:        true,
+		Lint:              "duplicate-rules",
+		LintFatal:         false,
+		FatalWarnings:     false,
+		FeatureFlags:      []string{"promql-experimental-functions", "utf8-names"},
+		PrometheusVersion: "2.53.0",
+	}
+	if got := newProviderSettings(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestProviderConfigure(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv(envBaseDir, "/etc/prometheus")
+	t.Setenv(envLint, "none")
+
+	p := New("test")().(*PromtoolProvider)
+
+	schemaResp := &provider.SchemaResponse{}
+	p.Schema(ctx, provider.SchemaRequest{}, schemaResp)
+	typ := schemaResp.Schema.Type().TerraformType(ctx)
+	alertPolicyType := typ.(tftypes.Object).AttributeTypes["alert_policy"].(tftypes.Object)
+	stringList := tftypes.List{ElementType: tftypes.String}
+
+	req := provider.ConfigureRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw: tftypes.NewValue(typ, map[string]tftypes.Value{
+				"base_dir":       tftypes.NewValue(tftypes.String, nil),
+				"syntax_only":    tftypes.NewValue(tftypes.Bool, true),
+				"lint":           tftypes.NewValue(tftypes.String, "duplicate-rules"),
+				"lint_fatal":     tftypes.NewValue(tftypes.Bool, nil),
+				"fatal_warnings": tftypes.NewValue(tftypes.Bool, true),
+				"feature_flags": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+					tftypes.NewValue(tftypes.String, "utf8-names"),
+				}),
+				"prometheus_version": tftypes.NewValue(tftypes.String, "3.0.0"),
+				"alert_policy": tftypes.NewValue(alertPolicyType, map[string]tftypes.Value{
+					"required_labels": tftypes.NewValue(tftypes.Map{ElementType: stringList}, map[string]tftypes.Value{
+						"severity": tftypes.NewValue(stringList, []tftypes.Value{
+							tftypes.NewValue(tftypes.String, "critical"),
+						}),
+					}),
+					"required_annotations": tftypes.NewValue(stringList, []tftypes.Value{
+						tftypes.NewValue(tftypes.String, "summary"),
+					}),
+					"url_annotations":       tftypes.NewValue(stringList, nil),
+					"camel_case_names":      tftypes.NewValue(tftypes.Bool, true),
+					"required_group_labels": tftypes.NewValue(stringList, nil),
+				}),
+			}),
+		},
+	}
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	expected := providerSettings{
+		BaseDir:           "/etc/prometheus",
+		SyntaxOnly:        true,
+		Lint:              "duplicate-rules",
+		LintFatal:         true,
+		FatalWarnings:     true,
+		FeatureFlags:      []string{"utf8-names"},
+		PrometheusVersion: "3.0.0",
+		AlertPolicy: &promtool.AlertPolicy{
+			RequiredLabels:      map[string][]string{"severity": {"critical"}},
+			RequiredAnnotations: []string{"summary"},
+			CamelCaseNames:      true,
+		},
+	}
+	if got := p.Settings(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, got)
+	}
+	if got, expected := p.FunctionSettings(), newProviderSettings(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected the functions to keep %+v, got %+v", expected, got)
+	}
+}
+
+func TestFunctionsIgnoreProviderConfiguration(t *testing.T) {
+	testFile, err := os.ReadFile("./testdata/rules_alert_policy.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// high_error_rate violates the policy of the provider, which only
+	// applies to the data sources.
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "promtool" {
+	alert_policy = {
+		camel_case_names = true
+	}
+}
+data "promtool_rule_group" "test" {
+	name = "test"
+	rule {
+		alert = "HighErrorRate"
+		expr  = "up == 0"
+	}
+}
+` + testAccCheckRulesConfig_basic(string(testFile)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("test", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestFunctionEnvironmentDefaults(t *testing.T) {
+	t.Setenv(envLintFatal, "false")
+
+	testFile, err := os.ReadFile("./testdata/rules_invalid_duplicate.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The settings are read from the environment when the provider is
+	// created.
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"promtool": providerserver.NewProtocol6WithError(New("test")()),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckRulesConfig_basic(string(testFile)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("test", "true"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &TestRulesFunction{}
+
+type TestRulesFunction struct {
+	provider *PromtoolProvider
+}
+
+func NewTestRulesFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &TestRulesFunction{
+			provider: p,
+		}
+	}
+}
+
+func (f *TestRulesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "test_rules"
+}
+
+func (f *TestRulesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Unit test Prometheus rules",
+		Description: "This function runs Prometheus rules unit tests against a rules configuration file, the same way `promtool test rules` does. The `rule_files` entry of the tests is ignored, the rules under test are always the ones given as first argument.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "config",
+				Description: "prometheus-rules-config",
+			},
+			function.StringParameter{
+				Name:        "tests",
+				Description: "prometheus-rules-unit-tests",
+			},
+		},
+		VariadicParameter: optionsParameter(promqlOptionsDescription),
+		Return:            function.BoolReturn{},
+	}
+}
+
+func (f *TestRulesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content, tests string
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &content, &tests, &options); resp.Error != nil {
+		return
+	}
+
+	var opts promqlOptions
+	if resp.Error = decodeOptions(ctx, options, 2, &opts); resp.Error != nil {
+		return
+	}
+
+	warnings, errs := promtool.RulesUnitTest(content, tests, opts.promtoolOptions(f.provider.FunctionSettings()))
+	for _, w := range warnings {
+		tflog.Warn(ctx, w.Error())
+	}
+	if len(errs) != 0 {
+		for _, err := range errs {
+			resp.Error = function.ConcatFuncErrors(resp.Error, &function.FuncError{Text: err.Error()})
+		}
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, true))
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestTestRules(t *testing.T) {
+	rules, err := os.ReadFile("./testdata/rules_valid.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_test_passing.yml",
+			Expected: true,
+		},
+		{
+			TestFile: "./testdata/rules_test_failing.yml",
+			Expected: false,
+			Error: `test unnamed#0:
+    alertname: HighRequestLatency, time: 15m,
+        diff (-exp +got):
+          (
+          	"""
+          	[
+          	0:
+        - 	  Labels:{alertname="HighRequestLatency", job="myjob", severity="warning"}
+        + 	  Labels:{alertname="HighRequestLatency", job="myjob", severity="page"}
+          	  Annotations:{summary="High request latency"}
+          	]
+          	"""
+          )`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccTestRulesConfig_basic(string(rules)))
+	}
+}
+
+func testAccTestRulesConfig_basic(rules string) PromtoolTerraformConfigBuilder {
+	return func(tests string) string {
+		return fmt.Sprintf(`
+locals {
+	rules = <<EOT
+%s
+EOT
+	tests = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::test_rules(local.rules, local.tests)
+}
+`, rules, tests)
+	}
+}
+
+func TestTestRulesFeatureFlags(t *testing.T) {
+	rules, err := os.ReadFile("./testdata/rules_experimental_functions.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests, err := os.ReadFile("./testdata/rules_test_experimental_functions.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccTestRulesConfig_options(string(rules), `{}`)(string(tests)),
+				ExpectError: regexp.MustCompile(`function "double_exponential_smoothing" is not enabled`),
+			},
+			{
+				Config: testAccTestRulesConfig_options(string(rules), `{ feature_flags = ["promql-experimental-functions"] }`)(string(tests)),
+				Check:  resource.TestCheckOutput("test", "true"),
+			},
+		},
+	})
+}
+
+func testAccTestRulesConfig_options(rules, options string) PromtoolTerraformConfigBuilder {
+	return func(tests string) string {
+		return fmt.Sprintf(`
+locals {
+	rules = <<EOT
+%s
+EOT
+	tests = <<EOT
+%s
+EOT
+}
+output "test" {
+	value = provider::promtool::test_rules(local.rules, local.tests, %s)
+}
+`, rules, tests, options)
+	}
+}
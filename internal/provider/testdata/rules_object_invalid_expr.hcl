@@ -0,0 +1,23 @@
+{
+  groups = [
+    {
+      name  = "recording"
+      limit = 10
+      rules = [
+        {
+          record = "job:request_latency_seconds:mean5m"
+          expr   = "avg by (job) (rate(request_latency_seconds_sum[5m]) / rate(request_latency_seconds_count[5m]))"
+        },
+      ]
+    },
+    {
+      name = "alerting"
+      rules = [
+        {
+          alert = "HighRequestLatency"
+          expr  = "job:request_latency_seconds:mean5m{job=\"myjob\"} >"
+        },
+      ]
+    },
+  ]
+}
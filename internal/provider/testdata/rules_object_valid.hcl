@@ -0,0 +1,21 @@
+{
+  groups = [
+    {
+      name = "example"
+      rules = [
+        {
+          record = "job:request_latency_seconds:mean5m"
+          expr   = "avg by (job) (rate(request_latency_seconds_sum[5m]) / rate(request_latency_seconds_count[5m]))"
+        },
+        {
+          alert = "HighRequestLatency"
+          expr  = "job:request_latency_seconds:mean5m{job=\"myjob\"} > 0.5"
+          for   = "10m"
+          labels = {
+            severity = "page"
+          }
+        },
+      ]
+    },
+  ]
+}
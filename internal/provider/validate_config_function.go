@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &ValidateConfigFunction{}
+
+var configDiagnosticAttrTypes = map[string]attr.Type{
+	"section": types.StringType,
+	"name":    types.StringType,
+	"line":    types.Int64Type,
+	"column":  types.Int64Type,
+	"path":    types.StringType,
+	"message": types.StringType,
+}
+
+type configDiagnosticModel struct {
+	Section string `tfsdk:"section"`
+	Name    string `tfsdk:"name"`
+	Line    int64  `tfsdk:"line"`
+	Column  int64  `tfsdk:"column"`
+	Path    string `tfsdk:"path"`
+	Message string `tfsdk:"message"`
+}
+
+type validateConfigResultModel struct {
+	Valid    bool                    `tfsdk:"valid"`
+	Errors   []configDiagnosticModel `tfsdk:"errors"`
+	Warnings []configDiagnosticModel `tfsdk:"warnings"`
+}
+
+func newConfigDiagnosticModels(diags []promtool.ConfigDiagnostic) []configDiagnosticModel {
+	models := make([]configDiagnosticModel, 0, len(diags))
+	for _, d := range diags {
+		m := configDiagnosticModel{
+			Section: d.Section,
+			Name:    d.Name,
+			Message: d.Error(),
+		}
+		if d.Rule != nil {
+			m.Line = int64(d.Rule.Line)
+			m.Column = int64(d.Rule.Column)
+			m.Path = d.Rule.Path
+		}
+		models = append(models, m)
+	}
+	return models
+}
+
+type ValidateConfigFunction struct {
+	provider *PromtoolProvider
+}
+
+func NewValidateConfigFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &ValidateConfigFunction{
+			provider: p,
+		}
+	}
+}
+
+func (f *ValidateConfigFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_config"
+}
+
+func (f *ValidateConfigFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Validate Prometheus configuration and report every problem",
+		Description: "This function validates a Prometheus configuration file like `check_config` does, but never fails and does not stop at the first problem. It returns an object whose `valid` attribute tells whether the configuration is valid, along with the list of `errors` found in the scrape configs, alertmanager configs, rule files and service discovery files, and the list of `warnings` that do not make the configuration invalid unless `fatal_warnings` is set. " +
+			"The problems found in the rule files with `check_rule_files` are named after their file, and have the `line`, `column` and `path`, such as `groups[2].rules[0].expr`, of the faulty field in that file when they are known.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "config",
+				Description: "prometheus-config",
+			},
+		},
+		VariadicParameter: optionsParameter(configOptionsDescription),
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"valid":    types.BoolType,
+				"errors":   types.ListType{ElemType: types.ObjectType{AttrTypes: configDiagnosticAttrTypes}},
+				"warnings": types.ListType{ElemType: types.ObjectType{AttrTypes: configDiagnosticAttrTypes}},
+			},
+		},
+	}
+}
+
+func (f *ValidateConfigFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content string
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &content, &options); resp.Error != nil {
+		return
+	}
+
+	var opts configOptions
+	if resp.Error = decodeOptions(ctx, options, 1, &opts); resp.Error != nil {
+		return
+	}
+
+	errs, warnings := promtool.ValidateConfig(content, opts.promtoolOptions(f.provider.FunctionSettings()))
+
+	result := validateConfigResultModel{
+		Valid:    len(errs) == 0,
+		Errors:   newConfigDiagnosticModels(errs),
+		Warnings: newConfigDiagnosticModels(warnings),
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_valid.yml",
+			Expected: true,
+			NonFatal: true,
+			Errors:   []string{},
+			Warnings: []string{},
+		},
+		{
+			TestFile: "./testdata/config_invalid.yml",
+			Expected: false,
+			NonFatal: true,
+			Errors: []string{
+				"config scrape_configs[2]: yaml: unmarshal errors:\n  line 20: field job_nzame not found in type config.ScrapeConfig",
+			},
+			Warnings: []string{},
+		},
+		{
+			TestFile: "./testdata/config_invalid_files.yml",
+			Expected: false,
+			NonFatal: true,
+			Errors: []string{
+				`rule_files /nonexistent/rules.yml: "/nonexistent/rules.yml" does not point to an existing file`,
+				`scrape_configs secured: error checking authorization credentials or bearer token file "/nonexistent/token": stat /nonexistent/token: no such file or directory`,
+				`scrape_configs secured: error checking client cert file "/nonexistent/cert.pem": stat /nonexistent/cert.pem: no such file or directory`,
+				`scrape_configs secured: error checking client key file "/nonexistent/key.pem": stat /nonexistent/key.pem: no such file or directory`,
+			},
+			Warnings: []string{},
+		},
+		{
+			TestFile: "./testdata/config_missing_sd_file.yml",
+			Expected: true,
+			NonFatal: true,
+			Errors:   []string{},
+			Warnings: []string{
+				`scrape_configs nodes: file "/nonexistent/targets/*.json" for file_sd in scrape job "nodes" does not exist`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccValidateConfig_options(`{}`))
+	}
+}
+
+func TestValidateConfigSyntaxOnly(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/config_invalid_files.yml",
+			Expected: true,
+			NonFatal: true,
+			Errors:   []string{},
+			Warnings: []string{},
+		},
+		{
+			TestFile: "./testdata/config_invalid_tls.yml",
+			Expected: false,
+			NonFatal: true,
+			Errors: []string{
+				"config: exactly one of key or key_file must be configured when a client certificate is configured",
+			},
+			Warnings: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccValidateConfig_options(`{ syntax_only = true }`))
+	}
+}
+
+func testAccValidateConfig_options(options string) PromtoolTerraformConfigBuilder {
+	return func(config string) string {
+		return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+	result = provider::promtool::validate_config(local.config, %s)
+}
+output "test" {
+	value = local.result.valid
+}
+output "errors" {
+	value = jsonencode([for e in local.result.errors : "${trimspace("${e.section} ${e.name}")}: ${e.message}"])
+}
+output "warnings" {
+	value = jsonencode([for w in local.result.warnings : "${trimspace("${w.section} ${w.name}")}: ${w.message}"])
+}
+`, config, options)
+	}
+}
+
+func TestValidateConfigRuleFilePositions(t *testing.T) {
+	testFile, err := os.ReadFile("./testdata/rules_invalid_expr.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccValidateConfig_ruleFiles(`{}`, string(testFile)),
+				Check:  resource.TestCheckOutput("test", `[{"column":11,"line":5,"name":"rules.yml","path":"groups[0].rules[0].expr","section":"rule_files"},{"column":13,"line":9,"name":"rules.yml","path":"groups[0].rules[1].record","section":"rule_files"}]`),
+			},
+			{
+				// The rule files are not checked along with syntax_only.
+				Config: testAccValidateConfig_ruleFiles(`{ syntax_only = true }`, string(testFile)),
+				Check:  resource.TestCheckOutput("test", `[]`),
+			},
+		},
+	})
+}
+
+func TestValidateConfigRuleFilesIgnored(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "test" {
+	value = jsonencode([for w in provider::promtool::validate_config("{}", { check_rule_files = true, syntax_only = true }).warnings : [w.section, w.message]])
+}
+`,
+				Check: resource.TestCheckOutput("test", `[["rule_files","the rule files are not checked when only the syntax of the configuration is checked"]]`),
+			},
+			{
+				Config: `
+output "test" {
+	value = jsonencode([for w in provider::promtool::validate_config("{}", { check_rule_files = true, agent = true }).warnings : [w.section, w.message]])
+}
+`,
+				Check: resource.TestCheckOutput("test", `[["config","no remote_write is configured, the samples scraped in agent mode are not sent anywhere"],["rule_files","the rule files are not checked in agent mode"]]`),
+			},
+		},
+	})
+}
+
+func testAccValidateConfig_ruleFiles(options, rules string) string {
+	return fmt.Sprintf(`
+locals {
+	rules = <<EOT
+%s
+EOT
+	options = merge(%s, {
+		check_rule_files = true
+		files            = { "rules.yml" = local.rules }
+	})
+}
+output "test" {
+	value = jsonencode([for e in provider::promtool::validate_config("rule_files: [rules.yml]", local.options).errors : { section = e.section, name = e.name, line = e.line, column = e.column, path = e.path }])
+}
+`, rules, options)
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lenstra/terraform-provider-promtool/internal/promtool"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &ValidateRulesFunction{}
+
+var ruleDiagnosticAttrTypes = map[string]attr.Type{
+	"group":   types.StringType,
+	"rule":    types.StringType,
+	"line":    types.Int64Type,
+	"column":  types.Int64Type,
+	"path":    types.StringType,
+	"kind":    types.StringType,
+	"message": types.StringType,
+}
+
+type ruleDiagnosticModel struct {
+	Group   string `tfsdk:"group"`
+	Rule    string `tfsdk:"rule"`
+	Line    int64  `tfsdk:"line"`
+	Column  int64  `tfsdk:"column"`
+	Path    string `tfsdk:"path"`
+	Kind    string `tfsdk:"kind"`
+	Message string `tfsdk:"message"`
+}
+
+type validateRulesResultModel struct {
+	Valid    bool                  `tfsdk:"valid"`
+	Errors   []ruleDiagnosticModel `tfsdk:"errors"`
+	Warnings []ruleDiagnosticModel `tfsdk:"warnings"`
+}
+
+func newRuleDiagnosticModels(diags []promtool.Diagnostic) []ruleDiagnosticModel {
+	models := make([]ruleDiagnosticModel, 0, len(diags))
+	for _, d := range diags {
+		models = append(models, ruleDiagnosticModel{
+			Group:   d.Group,
+			Rule:    d.Rule,
+			Line:    int64(d.Line),
+			Column:  int64(d.Column),
+			Path:    d.Path,
+			Kind:    d.Kind,
+			Message: d.Message,
+		})
+	}
+	return models
+}
+
+type ValidateRulesFunction struct {
+	provider *PromtoolProvider
+}
+
+func NewValidateRulesFunction(p *PromtoolProvider) func() function.Function {
+	return func() function.Function {
+		return &ValidateRulesFunction{
+			provider: p,
+		}
+	}
+}
+
+func (f *ValidateRulesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_rules"
+}
+
+func (f *ValidateRulesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Validate Prometheus rules configuration and report every problem",
+		Description: "This function validates a Prometheus rules configuration file like `check_rules` does, but never fails. It returns an object whose `valid` attribute tells whether the rules are valid, along with the list of `errors` and `warnings` found. Each of them has the `path` of the faulty field, such as `groups[2].rules[0].expr`, when it is known. The rules may also be given as an object, e.g. built in HCL, in which case `line` and `column` refer to its YAML encoding.",
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:        "config",
+				Description: rulesParameterDescription,
+			},
+		},
+		VariadicParameter: optionsParameter(rulesOptionsDescription),
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"valid":    types.BoolType,
+				"errors":   types.ListType{ElemType: types.ObjectType{AttrTypes: ruleDiagnosticAttrTypes}},
+				"warnings": types.ListType{ElemType: types.ObjectType{AttrTypes: ruleDiagnosticAttrTypes}},
+			},
+		},
+	}
+}
+
+func (f *ValidateRulesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var rules types.Dynamic
+	var options []types.Dynamic
+	if resp.Error = req.Arguments.Get(ctx, &rules, &options); resp.Error != nil {
+		return
+	}
+
+	content, _, funcErr := decodeRulesDocument(ctx, rules, 0)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	var opts rulesOptions
+	if resp.Error = decodeOptions(ctx, options, 1, &opts); resp.Error != nil {
+		return
+	}
+
+	errs, warnings := promtool.ValidateRules(content, opts.promtoolOptions(f.provider.FunctionSettings()))
+
+	result := validateRulesResultModel{
+		Valid:    len(errs) == 0,
+		Errors:   newRuleDiagnosticModels(errs),
+		Warnings: newRuleDiagnosticModels(warnings),
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
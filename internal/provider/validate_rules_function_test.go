@@ -0,0 +1,222 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidateRules(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Expected: true,
+			NonFatal: true,
+			Errors:   []string{},
+			Warnings: []string{},
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate.yml",
+			Expected: false,
+			NonFatal: true,
+			Errors: []string{
+				`groups[0].rules[1]: duplicate rule HighRequestLatency{severity="page"}, might cause inconsistency while recording expressions`,
+			},
+			Warnings: []string{},
+		},
+		{
+			TestFile: "./testdata/rules_invalid_expr.yml",
+			Expected: false,
+			NonFatal: true,
+			Errors: []string{
+				"groups[0].rules[0].expr: could not parse expression: 1:48: parse error: unexpected character inside braces: '>'",
+				"groups[0].rules[1].record: invalid field 'for' in recording rule",
+			},
+			Warnings: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccValidateRulesConfig_options(`{}`))
+	}
+}
+
+func TestValidateRulesLintNotFatal(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_valid.yml",
+			Expected: true,
+			NonFatal: true,
+			Errors:   []string{},
+			Warnings: []string{},
+		},
+		{
+			TestFile: "./testdata/rules_invalid_duplicate.yml",
+			Expected: true,
+			NonFatal: true,
+			Errors:   []string{},
+			Warnings: []string{
+				`groups[0].rules[1]: duplicate rule HighRequestLatency{severity="page"}, might cause inconsistency while recording expressions`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccValidateRulesConfig_options(`{ lint_fatal = false }`))
+	}
+}
+
+func TestValidateRulesObject(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_object_valid.hcl",
+			Expected: true,
+			NonFatal: true,
+			Errors:   []string{},
+			Warnings: []string{},
+		},
+		{
+			TestFile: "./testdata/rules_object_invalid_expr.hcl",
+			Expected: false,
+			NonFatal: true,
+			Errors: []string{
+				"groups[1].rules[0].expr: could not parse expression: 1:50: parse error: unexpected end of input",
+			},
+			Warnings: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccValidateRulesConfig_object)
+	}
+}
+
+func TestValidateRulesDuplicateGroupPath(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_invalid_duplicate_group_label.yml",
+			Options:  `{ prometheus_version = "2.53.0" }`,
+			Expected: false,
+			NonFatal: true,
+			Errors: []string{
+				`groups[1]: groupname: "example" is repeated in the same file`,
+				"groups[1].rules[0]: invalid label name: invalid-name",
+			},
+			Warnings: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccValidateRulesConfig_options(tt.Options))
+	}
+}
+
+func TestValidateRulesAlertPolicy(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_alert_policy.yml",
+			Options: `{
+		alert_policy = {
+			required_labels       = { severity = ["critical", "warning"] }
+			url_annotations       = ["runbook_url"]
+			required_group_labels = ["owner"]
+		}
+	}`,
+			Expected: false,
+			NonFatal: true,
+			Errors: []string{
+				`groups[0].rules[1]: alert high_error_rate violates the required_labels policy: label "severity" is "page", allowed values are critical, warning`,
+				`groups[0].rules[1]: alert high_error_rate violates the url_annotations policy: annotation "runbook_url" is not an absolute URL: "runbooks/errors"`,
+				`groups[0]: group example violates the required_group_labels policy: label "owner" is missing`,
+			},
+			Warnings: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccValidateRulesConfig_options(tt.Options))
+	}
+}
+
+func TestValidateRulesRecordingRuleNames(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_recording_rule_names.yml",
+			Options:  `{ lint = "recording-rule-names", lint_fatal = false }`,
+			Expected: true,
+			NonFatal: true,
+			Errors:   []string{},
+			Warnings: []string{
+				"groups[0].rules[1]: record instance:http_requests:rate5m has level instance but the outermost aggregation of its expression keeps by (job)",
+				"groups[0].rules[2]: record http_requests_rate5m is named like a raw metric, recording rules have to be named level:metric:operations",
+				"groups[0].rules[3]: record cluster:http_requests:rate5m has level cluster but the outermost aggregation of its expression keeps no label, its level has to be empty",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccValidateRulesConfig_options(tt.Options))
+	}
+}
+
+func TestValidateRulesPromQLAntiPatterns(t *testing.T) {
+	tests := []PromtoolTestCase{
+		{
+			TestFile: "./testdata/rules_promql_anti_patterns.yml",
+			Options:  `{ lint = "promql-anti-patterns", lint_fatal = false }`,
+			Expected: true,
+			NonFatal: true,
+			Errors:   []string{},
+			Warnings: []string{
+				"groups[0].rules[0].expr: record job:http_requests:rate5m: rate over the result of sum loses the counter resets of the series it aggregates, apply rate first then aggregate, e.g. sum(rate(...))",
+				"groups[0].rules[1].expr: record instance:node_memory_free_bytes:rate5m: rate of node_memory_free_bytes, which is not named like a counter, counters end with _total, _count, _sum, _bucket, use deriv or delta for gauges",
+				"groups[0].rules[2].expr: alert TargetMissing: absent(up) only returns a result when every series of up is missing, not when one of them is, select a single series with equality matchers or compare a count",
+				`groups[0].rules[3].expr: alert NegativeRate: rate(http_requests_total{handler=~".*api.*"}[5m]) < 0 can never be true, rate(http_requests_total{handler=~".*api.*"}[5m]) is always between 0 and +Inf`,
+				`groups[0].rules[3].expr: alert NegativeRate: handler=~".*api.*" is anchored by Prometheus, the leading and trailing .* make it match any value containing api and slow it down, remove them if this is not the intent`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.Run(t, testAccValidateRulesConfig_options(tt.Options))
+	}
+}
+
+func testAccValidateRulesConfig_options(options string) PromtoolTerraformConfigBuilder {
+	return func(config string) string {
+		return fmt.Sprintf(`
+locals {
+	config = <<EOT
+%s
+EOT
+	result = provider::promtool::validate_rules(local.config, %s)
+}
+output "test" {
+	value = local.result.valid
+}
+output "errors" {
+	value = jsonencode([for e in local.result.errors : "${e.path}: ${e.message}"])
+}
+output "warnings" {
+	value = jsonencode([for w in local.result.warnings : "${w.path}: ${w.message}"])
+}
+`, config, options)
+	}
+}
+
+func testAccValidateRulesConfig_object(rules string) string {
+	return fmt.Sprintf(`
+locals {
+	rules  = %s
+	result = provider::promtool::validate_rules(local.rules)
+}
+output "test" {
+	value = local.result.valid
+}
+output "errors" {
+	value = jsonencode([for e in local.result.errors : "${e.path}: ${e.message}"])
+}
+output "warnings" {
+	value = jsonencode([for w in local.result.warnings : "${w.path}: ${w.message}"])
+}
+`, rules)
+}